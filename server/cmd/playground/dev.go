@@ -2,12 +2,15 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -16,10 +19,14 @@ import (
 
 	"github.com/openjobspec/ojs-playground/server/internal/backends"
 	"github.com/openjobspec/ojs-playground/server/internal/chaos"
+	"github.com/openjobspec/ojs-playground/server/internal/cron"
 	"github.com/openjobspec/ojs-playground/server/internal/discovery"
 	"github.com/openjobspec/ojs-playground/server/internal/history"
+	"github.com/openjobspec/ojs-playground/server/internal/schema"
 	"github.com/openjobspec/ojs-playground/server/internal/server"
 	"github.com/openjobspec/ojs-playground/server/internal/sse"
+
+	spaembed "github.com/openjobspec/ojs-playground/server/internal/embed"
 )
 
 var devCmd = &cobra.Command{
@@ -32,11 +39,13 @@ func init() {
 	cfg := server.DefaultConfig()
 
 	devCmd.Flags().IntVarP(&cfg.Port, "port", "p", cfg.Port, "HTTP port")
-	devCmd.Flags().StringSliceVar(&cfg.Backends, "backend", cfg.Backends, "Backend(s) to enable: memory, redis, postgres")
+	devCmd.Flags().StringSliceVar(&cfg.Backends, "backend", cfg.Backends, "Backend(s) to enable: memory, redis, sqlite, postgres, or a name given to --backend-url")
+	devCmd.Flags().StringToStringVar(&cfg.BackendURLs, "backend-url", cfg.BackendURLs, "External OJS server as name=url (repeatable)")
 	devCmd.Flags().StringVar(&cfg.RedisURL, "redis-url", cfg.RedisURL, "Redis connection URL")
 	devCmd.Flags().StringVar(&cfg.PostgresURL, "postgres-url", cfg.PostgresURL, "PostgreSQL connection URL")
 	devCmd.Flags().StringVar(&cfg.ScanPorts, "scan-ports", cfg.ScanPorts, "Port range for worker discovery")
 	devCmd.Flags().BoolVar(&cfg.NoScan, "no-scan", cfg.NoScan, "Disable worker port scanning")
+	devCmd.Flags().BoolVar(&cfg.NoSnapshot, "no-snapshot", cfg.NoSnapshot, "Do not restore the memory backend on start or save it on shutdown")
 	devCmd.Flags().BoolVar(&cfg.OpenBrowser, "open", cfg.OpenBrowser, "Open browser on start")
 	devCmd.Flags().BoolVarP(&cfg.Verbose, "verbose", "v", cfg.Verbose, "Verbose logging")
 	devCmd.Flags().StringVar(&cfg.DataDir, "data-dir", cfg.DataDir, "Data directory for SQLite (default: ~/.ojs-playground)")
@@ -84,6 +93,12 @@ func runDev(cmd *cobra.Command, args []string) error {
 	defer store.Close()
 	slog.Info("history store initialized", "path", dbPath)
 
+	// Load the job args schemas registered in earlier runs
+	schemaRegistry, err := schema.NewRegistry(ctx, store)
+	if err != nil {
+		return fmt.Errorf("init schema registry: %w", err)
+	}
+
 	// Initialize SSE broadcaster
 	broadcaster := sse.NewBroadcaster()
 
@@ -92,6 +107,14 @@ func runDev(cmd *cobra.Command, args []string) error {
 
 	// Initialize worker registry
 	workerRegistry := discovery.NewRegistry(broadcaster)
+	workerRegistry.SetRegisterCallback(func(w *discovery.DiscoveredWorker) {
+		for jobType, raw := range w.Schemas {
+			s := &schema.ArgsSchema{JobType: jobType, Schema: raw, Source: "worker:" + w.Name}
+			if err := schemaRegistry.Register(ctx, s); err != nil {
+				slog.Warn("failed to register worker schema", "worker", w.Name, "job_type", jobType, "err", err)
+			}
+		}
+	})
 
 	// Initialize backend manager
 	activeBackend := "memory"
@@ -100,60 +123,150 @@ func runDev(cmd *cobra.Command, args []string) error {
 	}
 	backendManager := backends.NewManager(activeBackend)
 
-	// Create memory backend with state change callback
-	var memoryBackend *backends.MemoryBackend
-	memoryBackend = backends.NewMemoryBackend(func(job *backends.MemoryJob, fromState, toState string) {
-		// Record in history
-		now := time.Now()
-		histJob := &history.Job{
-			ID:          job.ID,
-			Type:        job.Type,
-			State:       toState,
-			Queue:       job.Queue,
-			Args:        job.Args,
-			Meta:        job.Meta,
-			Priority:    job.Priority,
-			Attempt:     job.Attempt,
-			MaxAttempts: job.MaxAttempts,
-			CreatedAt:   now,
-			UpdatedAt:   now,
-			Backend:     "memory",
-			Result:      job.Result,
-			Error:       job.Error,
-		}
-		if err := store.SaveJob(ctx, histJob); err != nil {
-			slog.Warn("failed to save job to history", "err", err)
-		}
-		if fromState != "" {
-			if err := store.UpdateJobState(ctx, job.ID, fromState, toState, ""); err != nil {
-				slog.Warn("failed to update job state in history", "err", err)
+	// Record job state changes in history and broadcast them, for every
+	// backend the playground drives jobs through
+	recordStateChange := func(backendName string) backends.StateChangeCallback {
+		return func(job *backends.MemoryJob, fromState, toState, reason string) {
+			// Record in history
+			now := time.Now()
+			histJob := &history.Job{
+				ID:          job.ID,
+				Type:        job.Type,
+				State:       toState,
+				Queue:       job.Queue,
+				Args:        job.Args,
+				Meta:        job.Meta,
+				Priority:    job.Priority,
+				Attempt:     job.Attempt,
+				MaxAttempts: job.MaxAttempts,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+				Backend:     backendName,
+				Result:      job.Result,
+				Error:       job.Error,
+			}
+			if err := store.SaveJob(ctx, histJob); err != nil {
+				slog.Warn("failed to save job to history", "err", err)
+			}
+			if fromState != "" {
+				if err := store.UpdateJobState(ctx, job.ID, fromState, toState, reason); err != nil {
+					slog.Warn("failed to update job state in history", "err", err)
+				}
 			}
+
+			// Broadcast SSE event
+			eventType := sse.EventJobStateChanged
+			switch toState {
+			case "completed":
+				eventType = sse.EventJobCompleted
+			case "retryable":
+				eventType = sse.EventJobFailed
+			case "discarded":
+				eventType = sse.EventJobDead
+			}
+			broadcaster.Broadcast(sse.Event{
+				Type:      eventType,
+				Timestamp: now,
+				JobID:     job.ID,
+				Queue:     job.Queue,
+				Data: map[string]any{
+					"job_id":     job.ID,
+					"type":       job.Type,
+					"from_state": fromState,
+					"to_state":   toState,
+					"queue":      job.Queue,
+				},
+			})
 		}
+	}
 
-		// Broadcast SSE event
-		eventType := sse.EventJobStateChanged
-		switch toState {
-		case "completed":
-			eventType = sse.EventJobCompleted
-		case "discarded":
-			eventType = sse.EventJobDead
+	// Create memory backend with state change callback
+	memoryBackend := backends.NewMemoryBackend(recordStateChange("memory"))
+	memoryBackend.SetBroadcaster(broadcaster)
+	memoryBackend.SetProgressCallback(func(jobID string, progress backends.ProgressUpdate) {
+		data, _ := json.Marshal(progress)
+		if err := store.UpdateJobProgress(ctx, jobID, data); err != nil {
+			slog.Warn("failed to update job progress in history", "err", err)
 		}
-		broadcaster.Broadcast(sse.Event{
-			Type:      eventType,
-			Timestamp: now,
-			JobID:     job.ID,
-			Queue:     job.Queue,
-			Data: map[string]any{
-				"job_id":     job.ID,
-				"type":       job.Type,
-				"from_state": fromState,
-				"to_state":   toState,
-				"queue":      job.Queue,
-			},
-		})
 	})
+	validateArgs := func(jobType, uri string, args json.RawMessage) []backends.FieldError {
+		var errs []backends.FieldError
+		for _, e := range schemaRegistry.ValidateArgs(jobType, uri, args) {
+			errs = append(errs, backends.FieldError{Path: schema.FieldPath(e.Path), Message: e.Message})
+		}
+		return errs
+	}
+	memoryBackend.SetArgsValidator(validateArgs)
+	memoryBackend.SetChaosConfig(chaosConfig)
 	backendManager.Register(memoryBackend)
 
+	// Connect to Redis if it is enabled and not an external OJS server
+	if _, external := cfg.BackendURLs["redis"]; slices.Contains(cfg.Backends, "redis") && !external {
+		redisBackend, err := backends.NewRedisBackend(ctx, cfg.RedisURL, recordStateChange("redis"))
+		if err != nil {
+			return fmt.Errorf("init redis backend: %w", err)
+		}
+		redisBackend.SetArgsValidator(validateArgs)
+		backendManager.Register(redisBackend)
+		slog.Info("backend registered", "name", "redis", "url", redisBackend.URL())
+	}
+
+	// Open the SQLite backend if it is enabled and not an external OJS server
+	if _, external := cfg.BackendURLs["sqlite"]; slices.Contains(cfg.Backends, "sqlite") && !external {
+		sqliteBackend, err := backends.NewSQLiteBackend(ctx, filepath.Join(cfg.DataDir, "backend.db"), recordStateChange("sqlite"))
+		if err != nil {
+			return fmt.Errorf("init sqlite backend: %w", err)
+		}
+		sqliteBackend.SetArgsValidator(validateArgs)
+		backendManager.Register(sqliteBackend)
+		slog.Info("backend registered", "name", "sqlite", "path", sqliteBackend.URL())
+	}
+
+	// Register external OJS servers
+	for name, url := range cfg.BackendURLs {
+		if name == "memory" {
+			return fmt.Errorf("backend url %q: the name memory is reserved", url)
+		}
+		b, err := backends.NewHTTPBackend(name, url, recordStateChange(name))
+		if err != nil {
+			return fmt.Errorf("backend %q: %w", name, err)
+		}
+		backendManager.Register(b)
+		slog.Info("backend registered", "name", name, "url", url)
+	}
+	if _, err := backendManager.Active(); err != nil {
+		return fmt.Errorf("active backend: %w (give its URL with --backend-url %s=<url>)", err, activeBackend)
+	}
+
+	// Restore the memory backend from the last shutdown
+	snapshotStore := backends.NewSnapshotStore(filepath.Join(cfg.DataDir, "snapshots"), memoryBackend)
+	if !cfg.NoSnapshot {
+		switch err := snapshotStore.Load(backends.AutosaveSnapshot); {
+		case err == nil:
+			slog.Info("memory backend restored", "snapshot", backends.AutosaveSnapshot)
+		case !errors.Is(err, backends.ErrSnapshotNotFound):
+			slog.Warn("failed to restore memory backend", "err", err)
+		}
+	}
+
+	// Initialize cron registry, enqueuing due jobs into the active backend
+	cronRegistry := cron.NewRegistry(func(ctx context.Context, template json.RawMessage) (string, error) {
+		var req backends.EnqueueRequest
+		if err := json.Unmarshal(template, &req); err != nil {
+			return "", err
+		}
+		jobs, err := backendManager.ActiveJobs()
+		if err != nil {
+			return "", err
+		}
+		job, err := jobs.EnqueueJob(ctx, &req)
+		if err != nil {
+			return "", err
+		}
+		return job.ID, nil
+	}, store, broadcaster)
+	defer cronRegistry.Stop()
+
 	// Start worker discovery (unless disabled)
 	if !cfg.NoScan {
 		scanner := discovery.NewScanner(cfg.ScanPorts)
@@ -168,6 +281,7 @@ func runDev(cmd *cobra.Command, args []string) error {
 					Port:     ep.Port,
 					Queues:   ep.Manifest.Queues,
 					JobTypes: ep.Manifest.JobTypes,
+					Schemas:  ep.Manifest.Schemas,
 				})
 			}
 			slog.Info("worker scan complete", "found", len(endpoints))
@@ -179,6 +293,12 @@ func runDev(cmd *cobra.Command, args []string) error {
 	}
 
 	// Build router with all dependencies
+	// Load the bundled OJS schemas for validating enqueued jobs
+	validator, err := schema.New(spaembed.Schemas())
+	if err != nil {
+		slog.Warn("job schema validation disabled", "err", err)
+	}
+
 	deps := &server.Deps{
 		Config:         cfg,
 		Store:          store,
@@ -187,8 +307,15 @@ func runDev(cmd *cobra.Command, args []string) error {
 		MemoryBackend:  memoryBackend,
 		ChaosConfig:    chaosConfig,
 		WorkerRegistry: workerRegistry,
+		CronRegistry:   cronRegistry,
+		SnapshotStore:  snapshotStore,
+		Validator:      validator,
+		SchemaRegistry: schemaRegistry,
+	}
+	router, err := server.NewRouter(deps)
+	if err != nil {
+		return fmt.Errorf("build router: %w", err)
 	}
-	router := server.NewRouter(deps)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -224,6 +351,11 @@ func runDev(cmd *cobra.Command, args []string) error {
 	}
 
 	backendManager.Close()
+	if !cfg.NoSnapshot {
+		if _, err := snapshotStore.Save(backends.AutosaveSnapshot); err != nil {
+			slog.Error("failed to save memory backend", "err", err)
+		}
+	}
 	slog.Info("server stopped")
 	return nil
 }
@@ -46,21 +46,55 @@ func (h *BackendHandler) Stats(w http.ResponseWriter, r *http.Request) {
 }
 
 // Pause handles POST /api/backends/{name}/pause.
+// With ?queue=name only that queue is paused.
 func (h *BackendHandler) Pause(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
-	if _, ok := h.manager.Get(name); !ok {
-		WriteError(w, http.StatusNotFound, "Backend not found: "+name)
+	p, ok := h.pausable(w, name)
+	if !ok {
+		return
+	}
+
+	if queue := r.URL.Query().Get("queue"); queue != "" {
+		p.PauseQueue(queue)
+		WriteJSON(w, http.StatusOK, map[string]any{"status": "paused", "backend": name, "queue": queue})
 		return
 	}
+
+	p.Pause()
 	WriteJSON(w, http.StatusOK, map[string]any{"status": "paused", "backend": name})
 }
 
 // Resume handles POST /api/backends/{name}/resume.
+// With ?queue=name only that queue is resumed.
 func (h *BackendHandler) Resume(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
-	if _, ok := h.manager.Get(name); !ok {
-		WriteError(w, http.StatusNotFound, "Backend not found: "+name)
+	p, ok := h.pausable(w, name)
+	if !ok {
+		return
+	}
+
+	if queue := r.URL.Query().Get("queue"); queue != "" {
+		p.ResumeQueue(queue)
+		WriteJSON(w, http.StatusOK, map[string]any{"status": "active", "backend": name, "queue": queue})
 		return
 	}
+
+	p.Resume()
 	WriteJSON(w, http.StatusOK, map[string]any{"status": "active", "backend": name})
 }
+
+// pausable looks up a backend that supports pausing, writing an error
+// response if there is none.
+func (h *BackendHandler) pausable(w http.ResponseWriter, name string) (backends.Pausable, bool) {
+	b, ok := h.manager.Get(name)
+	if !ok {
+		WriteError(w, http.StatusNotFound, "Backend not found: "+name)
+		return nil, false
+	}
+	p, ok := b.(backends.Pausable)
+	if !ok {
+		WriteError(w, http.StatusNotImplemented, "Backend does not support pausing: "+name)
+		return nil, false
+	}
+	return p, true
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openjobspec/ojs-playground/server/internal/cron"
+	"github.com/openjobspec/ojs-playground/server/internal/history"
+)
+
+// CronHandler handles periodic job endpoints. It serves both /api/cron and
+// /ojs/v1/cron.
+type CronHandler struct {
+	registry *cron.Registry
+	store    history.Store
+}
+
+// NewCronHandler creates a new CronHandler.
+func NewCronHandler(registry *cron.Registry, store history.Store) *CronHandler {
+	return &CronHandler{registry: registry, store: store}
+}
+
+// Routes registers the cron endpoints on r.
+func (h *CronHandler) Routes(r chi.Router) {
+	r.Get("/", h.List)
+	r.Post("/", h.Register)
+	r.Get("/{name}", h.Get)
+	r.Delete("/{name}", h.Delete)
+	r.Post("/{name}/pause", h.Pause)
+	r.Post("/{name}/resume", h.Resume)
+}
+
+// List handles GET /cron.
+func (h *CronHandler) List(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string]any{"cron": h.registry.List()})
+}
+
+// Register handles POST /cron.
+func (h *CronHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var entry cron.Entry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	if err := h.registry.Register(&entry); err != nil {
+		if errors.Is(err, cron.ErrExists) {
+			WriteError(w, http.StatusConflict, "Cron entry already exists: "+entry.Name)
+			return
+		}
+		WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	created, _ := h.registry.Get(entry.Name)
+	WriteJSON(w, http.StatusCreated, map[string]any{"cron": created})
+}
+
+// Get handles GET /cron/{name}, including recent firings.
+func (h *CronHandler) Get(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	entry, ok := h.registry.Get(name)
+	if !ok {
+		WriteError(w, http.StatusNotFound, "Cron entry not found: "+name)
+		return
+	}
+
+	var firings []history.CronFiring
+	if h.store != nil {
+		firings, _ = h.store.ListCronFirings(r.Context(), name, 50)
+	}
+	if firings == nil {
+		firings = []history.CronFiring{}
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"cron":    entry,
+		"firings": firings,
+	})
+}
+
+// Delete handles DELETE /cron/{name}.
+func (h *CronHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	if err := h.registry.Delete(name); err != nil {
+		WriteError(w, http.StatusNotFound, "Cron entry not found: "+name)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{"status": "removed", "name": name})
+}
+
+// Pause handles POST /cron/{name}/pause.
+func (h *CronHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, true)
+}
+
+// Resume handles POST /cron/{name}/resume.
+func (h *CronHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, false)
+}
+
+func (h *CronHandler) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	name := chi.URLParam(r, "name")
+
+	entry, err := h.registry.SetPaused(name, paused)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "Cron entry not found: "+name)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{"cron": entry})
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openjobspec/ojs-playground/server/internal/backends"
+)
+
+// DLQHandler handles dead letter queue endpoints.
+type DLQHandler struct {
+	memory *backends.MemoryBackend
+}
+
+// NewDLQHandler creates a new DLQHandler.
+func NewDLQHandler(memory *backends.MemoryBackend) *DLQHandler {
+	return &DLQHandler{memory: memory}
+}
+
+// List handles GET /api/dlq.
+func (h *DLQHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := backends.DeadLetterFilter{DeadLetterQueue: r.URL.Query().Get("queue")}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		filter.Limit, _ = strconv.Atoi(limitStr)
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		filter.Offset, _ = strconv.Atoi(offsetStr)
+	}
+
+	if h.memory == nil {
+		WriteJSON(w, http.StatusOK, map[string]any{"dead": []any{}, "total": 0})
+		return
+	}
+
+	dead, total := h.memory.ListDeadLetters(filter)
+	if dead == nil {
+		dead = []*backends.DeadLetter{}
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"dead": dead, "total": total})
+}
+
+// Get handles GET /api/dlq/{id}.
+func (h *DLQHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if h.memory == nil {
+		WriteError(w, http.StatusNotFound, "Dead letter not found: "+id)
+		return
+	}
+
+	dl, ok := h.memory.GetDeadLetter(id)
+	if !ok {
+		WriteError(w, http.StatusNotFound, "Dead letter not found: "+id)
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"dead": dl})
+}
+
+// Replay handles POST /api/dlq/{id}/replay.
+func (h *DLQHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if h.memory == nil {
+		WriteError(w, http.StatusNotFound, "Dead letter not found: "+id)
+		return
+	}
+
+	replayed := h.memory.ReplayDeadLetters([]string{id}, "")
+	if len(replayed) == 0 {
+		WriteError(w, http.StatusNotFound, "Dead letter not found: "+id)
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"job": replayed[0]})
+}
+
+// ReplayBulk handles POST /api/dlq/replay. The body selects jobs by
+// {"ids": [...]} or {"queue": "..."}; an empty body replays everything.
+func (h *DLQHandler) ReplayBulk(w http.ResponseWriter, r *http.Request) {
+	sel, err := backends.DecodeDeadSelection(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	var replayed []*backends.MemoryJob
+	if h.memory != nil {
+		replayed = h.memory.ReplayDeadLetters(sel.IDs, sel.Queue)
+	}
+	if replayed == nil {
+		replayed = []*backends.MemoryJob{}
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"jobs": replayed, "replayed": len(replayed)})
+}
+
+// Delete handles DELETE /api/dlq/{id}.
+func (h *DLQHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if h.memory == nil || h.memory.PurgeDeadLetters([]string{id}, "") == 0 {
+		WriteError(w, http.StatusNotFound, "Dead letter not found: "+id)
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"purged": 1})
+}
+
+// Purge handles DELETE /api/dlq, with the same selection as ReplayBulk,
+// except that purging everything takes {"all": true}.
+func (h *DLQHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	sel, err := backends.DecodeDeadSelection(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+	if sel.Empty() {
+		WriteError(w, http.StatusBadRequest, backends.ErrPurgeNeedsSelection.Error())
+		return
+	}
+
+	purged := 0
+	if h.memory != nil {
+		purged = h.memory.PurgeDeadLetters(sel.IDs, sel.Queue)
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"purged": purged})
+}
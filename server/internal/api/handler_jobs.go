@@ -2,43 +2,40 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
 
 	"github.com/openjobspec/ojs-playground/server/internal/backends"
 	"github.com/openjobspec/ojs-playground/server/internal/history"
-	"github.com/openjobspec/ojs-playground/server/internal/sse"
 )
 
-// JobHandler handles playground job endpoints.
+// JobHandler handles playground job endpoints. Jobs are created, cancelled
+// and retried through the active backend, which records the resulting state
+// changes in history; listing and lookup read history.
 type JobHandler struct {
-	store       history.Store
-	memory      *backends.MemoryBackend
-	broadcaster *sse.Broadcaster
-	backendName string
+	store    history.Store
+	backends *backends.Manager
 }
 
 // NewJobHandler creates a new JobHandler.
-func NewJobHandler(store history.Store, memory *backends.MemoryBackend, broadcaster *sse.Broadcaster, backendName string) *JobHandler {
+func NewJobHandler(store history.Store, manager *backends.Manager) *JobHandler {
 	return &JobHandler{
-		store:       store,
-		memory:      memory,
-		broadcaster: broadcaster,
-		backendName: backendName,
+		store:    store,
+		backends: manager,
 	}
 }
 
-// Create handles POST /api/jobs — submit a job via playground API.
+// Create handles POST /api/jobs — submit a job via playground API. The body
+// is an OJS enqueue request, which may also give the queue at the top level.
+// As on /ojs/v1/jobs, a job a unique policy matched to an existing one is
+// answered with 200 and that job.
 func (h *JobHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Type    string          `json:"type"`
-		Args    json.RawMessage `json:"args,omitempty"`
-		Queue   string          `json:"queue,omitempty"`
-		Options json.RawMessage `json:"options,omitempty"`
+		backends.EnqueueRequest
+		Queue string `json:"queue,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -54,55 +51,66 @@ func (h *JobHandler) Create(w http.ResponseWriter, r *http.Request) {
 	if req.Args == nil {
 		req.Args = json.RawMessage(`[]`)
 	}
-	if req.Queue == "" {
-		req.Queue = "default"
-	}
-
-	uid, _ := uuid.NewV7()
-	id := uid.String()
-	now := time.Now()
-
-	// Save to history store
-	job := &history.Job{
-		ID:          id,
-		Type:        req.Type,
-		State:       "available",
-		Queue:       req.Queue,
-		Args:        req.Args,
-		Priority:    0,
-		Attempt:     0,
-		MaxAttempts: 3,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-		Backend:     h.backendName,
-	}
-
-	if h.store != nil {
-		if err := h.store.SaveJob(r.Context(), job); err != nil {
-			WriteError(w, http.StatusInternalServerError, "Failed to save job: "+err.Error())
-			return
+	if req.Queue != "" {
+		if req.Options == nil {
+			req.Options = &backends.EnqueueOptions{}
+		}
+		if req.Options.Queue == "" {
+			req.Options.Queue = req.Queue
 		}
 	}
 
-	// Broadcast SSE event
-	if h.broadcaster != nil {
-		h.broadcaster.Broadcast(sse.Event{
-			Type:      sse.EventJobStateChanged,
-			Timestamp: now,
-			JobID:     id,
-			Queue:     req.Queue,
-			Data: map[string]any{
-				"job_id": id,
-				"type":   req.Type,
-				"state":  "available",
-				"queue":  req.Queue,
-			},
-		})
+	jobs, ok := h.activeJobs(w)
+	if !ok {
+		return
 	}
 
+	var job *backends.MemoryJob
+	created := true
+	var err error
+	if e, ok := jobs.(backends.Enqueuer); ok {
+		job, created, err = e.EnqueueContext(r.Context(), &req.EnqueueRequest)
+	} else {
+		job, err = jobs.EnqueueJob(r.Context(), &req.EnqueueRequest)
+	}
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	if !created {
+		WriteJSON(w, http.StatusOK, map[string]any{"job": job})
+		return
+	}
 	WriteJSON(w, http.StatusCreated, map[string]any{"job": job})
 }
 
+// Batch handles POST /api/jobs/batch — submit several jobs at once to the
+// active backend, if it takes batches.
+func (h *JobHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	var req backends.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	jobs, ok := h.activeJobs(w)
+	if !ok {
+		return
+	}
+	batcher, ok := jobs.(backends.Batcher)
+	if !ok {
+		WriteError(w, http.StatusNotImplemented, "Backend "+h.backends.ActiveName()+" does not support batch enqueue.")
+		return
+	}
+
+	resp, err := batcher.EnqueueBatch(&req)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
 // List handles GET /api/jobs.
 func (h *JobHandler) List(w http.ResponseWriter, r *http.Request) {
 	filter := history.ListFilter{
@@ -169,38 +177,16 @@ func (h *JobHandler) Get(w http.ResponseWriter, r *http.Request) {
 func (h *JobHandler) Cancel(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	if h.store == nil {
-		WriteError(w, http.StatusNotFound, "Job not found: "+id)
+	jobs, ok := h.activeJobs(w)
+	if !ok {
 		return
 	}
 
-	job, err := h.store.GetJob(r.Context(), id)
+	job, err := jobs.CancelJob(r.Context(), id)
 	if err != nil {
-		WriteError(w, http.StatusNotFound, "Job not found: "+id)
-		return
-	}
-
-	fromState := job.State
-	if err := h.store.UpdateJobState(r.Context(), id, fromState, "cancelled", "Cancelled via playground"); err != nil {
-		WriteError(w, http.StatusInternalServerError, "Failed to cancel: "+err.Error())
+		writeBackendError(w, err)
 		return
 	}
-
-	if h.broadcaster != nil {
-		h.broadcaster.Broadcast(sse.Event{
-			Type:      sse.EventJobStateChanged,
-			Timestamp: time.Now(),
-			JobID:     id,
-			Queue:     job.Queue,
-			Data: map[string]any{
-				"job_id":     id,
-				"from_state": fromState,
-				"to_state":   "cancelled",
-			},
-		})
-	}
-
-	job.State = "cancelled"
 	WriteJSON(w, http.StatusOK, map[string]any{"job": job})
 }
 
@@ -208,37 +194,51 @@ func (h *JobHandler) Cancel(w http.ResponseWriter, r *http.Request) {
 func (h *JobHandler) Retry(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	if h.store == nil {
-		WriteError(w, http.StatusNotFound, "Job not found: "+id)
+	jobs, ok := h.activeJobs(w)
+	if !ok {
 		return
 	}
 
-	job, err := h.store.GetJob(r.Context(), id)
+	job, err := jobs.RetryJob(r.Context(), id)
 	if err != nil {
-		WriteError(w, http.StatusNotFound, "Job not found: "+id)
+		writeBackendError(w, err)
 		return
 	}
+	WriteJSON(w, http.StatusOK, map[string]any{"job": job})
+}
 
-	fromState := job.State
-	if err := h.store.UpdateJobState(r.Context(), id, fromState, "available", "Retried via playground"); err != nil {
-		WriteError(w, http.StatusInternalServerError, "Failed to retry: "+err.Error())
-		return
+// activeJobs returns the job operations of the active backend, writing an
+// error response if it has none.
+func (h *JobHandler) activeJobs(w http.ResponseWriter) (backends.JobBackend, bool) {
+	if h.backends == nil {
+		WriteError(w, http.StatusServiceUnavailable, "No backend is available for job operations.")
+		return nil, false
 	}
-
-	if h.broadcaster != nil {
-		h.broadcaster.Broadcast(sse.Event{
-			Type:      sse.EventJobStateChanged,
-			Timestamp: time.Now(),
-			JobID:     id,
-			Queue:     job.Queue,
-			Data: map[string]any{
-				"job_id":     id,
-				"from_state": fromState,
-				"to_state":   "available",
-			},
-		})
+	jobs, err := h.backends.ActiveJobs()
+	if err != nil {
+		WriteError(w, http.StatusServiceUnavailable, "No backend is available for job operations: "+err.Error())
+		return nil, false
 	}
+	return jobs, true
+}
 
-	job.State = "available"
-	WriteJSON(w, http.StatusOK, map[string]any{"job": job})
+// writeBackendError reports an error from a backend operation. Errors the
+// backend returned keep their status, code and details; a backend that
+// could not be reached is a bad gateway.
+func writeBackendError(w http.ResponseWriter, err error) {
+	var reqErr *backends.RequestError
+	if errors.As(err, &reqErr) {
+		body := map[string]any{
+			"message":    reqErr.Message,
+			"status":     reqErr.Status,
+			"code":       reqErr.Code,
+			"request_id": w.Header().Get("X-Request-Id"),
+		}
+		for k, v := range reqErr.Details {
+			body[k] = v
+		}
+		WriteJSON(w, reqErr.Status, map[string]any{"error": body})
+		return
+	}
+	WriteError(w, http.StatusBadGateway, "Backend request failed: "+err.Error())
 }
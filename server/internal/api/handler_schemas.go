@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openjobspec/ojs-playground/server/internal/schema"
+)
+
+// SchemaHandler handles the job args schema registry endpoints.
+type SchemaHandler struct {
+	registry *schema.Registry
+}
+
+// NewSchemaHandler creates a new SchemaHandler.
+func NewSchemaHandler(registry *schema.Registry) *SchemaHandler {
+	return &SchemaHandler{registry: registry}
+}
+
+// List handles GET /api/schemas, optionally filtered by ?job_type=.
+func (h *SchemaHandler) List(w http.ResponseWriter, r *http.Request) {
+	schemas := []schema.ArgsSchema{}
+	if h.registry != nil {
+		schemas = h.registry.List(r.URL.Query().Get("job_type"))
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"schemas": schemas})
+}
+
+// Register handles POST /api/schemas. Registering a URI that is already
+// taken replaces its schema.
+func (h *SchemaHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var s schema.ArgsSchema
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	if h.registry == nil {
+		WriteError(w, http.StatusServiceUnavailable, "The schema registry is not available.")
+		return
+	}
+
+	s.Source = "api"
+	if err := h.registry.Register(r.Context(), &s); err != nil {
+		WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusCreated, map[string]any{"schema": s})
+}
+
+// Get handles GET /api/schemas/{uri}. The URI is path-escaped.
+func (h *SchemaHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uri := schemaURI(r)
+
+	var s schema.ArgsSchema
+	ok := false
+	if h.registry != nil {
+		s, ok = h.registry.Get(uri)
+	}
+	if !ok {
+		WriteError(w, http.StatusNotFound, "Schema not found: "+uri)
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"schema": s})
+}
+
+// Delete handles DELETE /api/schemas/{uri}.
+func (h *SchemaHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uri := schemaURI(r)
+
+	err := schema.ErrSchemaNotFound
+	if h.registry != nil {
+		err = h.registry.Delete(r.Context(), uri)
+	}
+	if errors.Is(err, schema.ErrSchemaNotFound) {
+		WriteError(w, http.StatusNotFound, "Schema not found: "+uri)
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"status": "deleted", "uri": uri})
+}
+
+// schemaURI returns the unescaped {uri} path parameter.
+func schemaURI(r *http.Request) string {
+	uri := chi.URLParam(r, "uri")
+	if unescaped, err := url.PathUnescape(uri); err == nil {
+		return unescaped
+	}
+	return uri
+}
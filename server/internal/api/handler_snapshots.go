@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openjobspec/ojs-playground/server/internal/backends"
+)
+
+// SnapshotHandler handles memory backend snapshot endpoints.
+type SnapshotHandler struct {
+	snapshots *backends.SnapshotStore
+}
+
+// NewSnapshotHandler creates a new SnapshotHandler.
+func NewSnapshotHandler(snapshots *backends.SnapshotStore) *SnapshotHandler {
+	return &SnapshotHandler{snapshots: snapshots}
+}
+
+// List handles GET /api/snapshots.
+func (h *SnapshotHandler) List(w http.ResponseWriter, r *http.Request) {
+	if h.snapshots == nil {
+		WriteJSON(w, http.StatusOK, map[string]any{"snapshots": []any{}})
+		return
+	}
+
+	snapshots, err := h.snapshots.List()
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to list snapshots: "+err.Error())
+		return
+	}
+	if snapshots == nil {
+		snapshots = []*backends.SnapshotInfo{}
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"snapshots": snapshots})
+}
+
+// Save handles POST /api/snapshots — save the memory backend under a name.
+func (h *SnapshotHandler) Save(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	if !backends.ValidSnapshotName(req.Name) {
+		WriteError(w, http.StatusUnprocessableEntity, "Field 'name' must be 1-128 letters, digits, '.', '_' or '-', starting with a letter or digit.")
+		return
+	}
+
+	if h.snapshots == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Snapshots require the in-memory backend.")
+		return
+	}
+
+	info, err := h.snapshots.Save(req.Name)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to save snapshot: "+err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusCreated, map[string]any{"snapshot": info})
+}
+
+// Load handles POST /api/snapshots/{name}/load — replace the memory
+// backend's state with a saved snapshot.
+func (h *SnapshotHandler) Load(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	if h.snapshots == nil {
+		WriteError(w, http.StatusNotFound, "Snapshot not found: "+name)
+		return
+	}
+
+	if err := h.snapshots.Load(name); err != nil {
+		if errors.Is(err, backends.ErrSnapshotNotFound) {
+			WriteError(w, http.StatusNotFound, "Snapshot not found: "+name)
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to load snapshot: "+err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"loaded": name})
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/openjobspec/ojs-playground/server/internal/schema"
+)
+
+// ValidateHandler checks documents against the bundled OJS schemas.
+type ValidateHandler struct {
+	validator *schema.Validator
+}
+
+// NewValidateHandler creates a new ValidateHandler.
+func NewValidateHandler(validator *schema.Validator) *ValidateHandler {
+	return &ValidateHandler{validator: validator}
+}
+
+// Validate handles POST /api/validate. The body is validated as a job
+// envelope, or against the schema named by the ?schema= query parameter,
+// and the result has the same shape as the UI editor's.
+func (h *ValidateHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	if h.validator == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Schema validation is not available")
+		return
+	}
+
+	name := r.URL.Query().Get("schema")
+	if name == "" {
+		name = schema.Job
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Failed to read request body: "+err.Error())
+		return
+	}
+
+	errs, err := h.validator.Validate(name, body)
+	switch {
+	case errors.Is(err, schema.ErrUnknownSchema):
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		errs = []schema.Error{schema.ParseError(err)}
+	case errs == nil:
+		errs = []schema.Error{}
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"valid":  len(errs) == 0,
+		"schema": name,
+		"errors": errs,
+	})
+}
@@ -7,17 +7,19 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/openjobspec/ojs-playground/server/internal/backends"
 	"github.com/openjobspec/ojs-playground/server/internal/discovery"
 )
 
 // WorkerHandler handles worker discovery endpoints.
 type WorkerHandler struct {
 	registry *discovery.Registry
+	memory   *backends.MemoryBackend
 }
 
 // NewWorkerHandler creates a new WorkerHandler.
-func NewWorkerHandler(registry *discovery.Registry) *WorkerHandler {
-	return &WorkerHandler{registry: registry}
+func NewWorkerHandler(registry *discovery.Registry, memory *backends.MemoryBackend) *WorkerHandler {
+	return &WorkerHandler{registry: registry, memory: memory}
 }
 
 // List handles GET /api/workers.
@@ -36,11 +38,12 @@ func (h *WorkerHandler) List(w http.ResponseWriter, r *http.Request) {
 // Register handles POST /api/workers — manual worker registration.
 func (h *WorkerHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name     string   `json:"name"`
-		URL      string   `json:"url"`
-		Port     int      `json:"port,omitempty"`
-		Queues   []string `json:"queues,omitempty"`
-		JobTypes []string `json:"job_types,omitempty"`
+		Name     string                     `json:"name"`
+		URL      string                     `json:"url"`
+		Port     int                        `json:"port,omitempty"`
+		Queues   []string                   `json:"queues,omitempty"`
+		JobTypes []string                   `json:"job_types,omitempty"`
+		Schemas  map[string]json.RawMessage `json:"schemas,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -60,6 +63,7 @@ func (h *WorkerHandler) Register(w http.ResponseWriter, r *http.Request) {
 		Port:     req.Port,
 		Queues:   req.Queues,
 		JobTypes: req.JobTypes,
+		Schemas:  req.Schemas,
 	}
 
 	h.registry.Register(worker)
@@ -93,3 +97,17 @@ func (h *WorkerHandler) Drain(w http.ResponseWriter, r *http.Request) {
 	h.registry.MarkDisconnected(id)
 	WriteJSON(w, http.StatusOK, map[string]any{"status": "draining", "worker": worker})
 }
+
+// Jobs handles GET /api/workers/{id}/jobs — the jobs a worker is
+// processing. The ID is the worker_id the worker fetches with.
+func (h *WorkerHandler) Jobs(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	jobs := []*backends.MemoryJob{}
+	if h.memory != nil {
+		if held := h.memory.WorkerJobs(id); held != nil {
+			jobs = held
+		}
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"worker_id": id, "jobs": jobs})
+}
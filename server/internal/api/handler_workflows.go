@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openjobspec/ojs-playground/server/internal/backends"
+)
+
+// WorkflowHandler handles workflow endpoints.
+type WorkflowHandler struct {
+	memory *backends.MemoryBackend
+}
+
+// NewWorkflowHandler creates a new WorkflowHandler.
+func NewWorkflowHandler(memory *backends.MemoryBackend) *WorkflowHandler {
+	return &WorkflowHandler{memory: memory}
+}
+
+// List handles GET /api/workflows.
+func (h *WorkflowHandler) List(w http.ResponseWriter, r *http.Request) {
+	if h.memory == nil {
+		WriteJSON(w, http.StatusOK, map[string]any{"workflows": []any{}})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"workflows": h.memory.ListWorkflows()})
+}
+
+// Create handles POST /api/workflows — submit a chain or group.
+func (h *WorkflowHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req backends.WorkflowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	if h.memory == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Workflows require the in-memory backend.")
+		return
+	}
+
+	wf, err := h.memory.SubmitWorkflow(&req)
+	if err != nil {
+		WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusCreated, map[string]any{"workflow": wf})
+}
+
+// Get handles GET /api/workflows/{id}.
+func (h *WorkflowHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if h.memory == nil {
+		WriteError(w, http.StatusNotFound, "Workflow not found: "+id)
+		return
+	}
+
+	wf, ok := h.memory.GetWorkflow(id)
+	if !ok {
+		WriteError(w, http.StatusNotFound, "Workflow not found: "+id)
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"workflow": wf})
+}
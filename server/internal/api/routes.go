@@ -5,8 +5,10 @@ import (
 
 	"github.com/openjobspec/ojs-playground/server/internal/backends"
 	"github.com/openjobspec/ojs-playground/server/internal/chaos"
+	"github.com/openjobspec/ojs-playground/server/internal/cron"
 	"github.com/openjobspec/ojs-playground/server/internal/discovery"
 	"github.com/openjobspec/ojs-playground/server/internal/history"
+	"github.com/openjobspec/ojs-playground/server/internal/schema"
 	"github.com/openjobspec/ojs-playground/server/internal/sse"
 )
 
@@ -18,6 +20,10 @@ type RouteDeps struct {
 	MemoryBackend   *backends.MemoryBackend
 	ChaosConfig     *chaos.Config
 	WorkerRegistry  *discovery.Registry
+	CronRegistry    *cron.Registry
+	SnapshotStore   *backends.SnapshotStore
+	Validator       *schema.Validator
+	SchemaRegistry  *schema.Registry
 	Port            int
 	BackendNames    []string
 }
@@ -25,19 +31,31 @@ type RouteDeps struct {
 // RegisterRoutes registers all API routes on the given chi router.
 func RegisterRoutes(r chi.Router, deps *RouteDeps) {
 	healthHandler := NewHealthHandler(deps.Port, deps.BackendNames)
-	jobHandler := NewJobHandler(deps.Store, deps.MemoryBackend, deps.Broadcaster, deps.BackendManager.ActiveName())
+	jobHandler := NewJobHandler(deps.Store, deps.BackendManager)
 	backendHandler := NewBackendHandler(deps.BackendManager)
-	workerHandler := NewWorkerHandler(deps.WorkerRegistry)
+	workerHandler := NewWorkerHandler(deps.WorkerRegistry, deps.MemoryBackend)
 	chaosHandler := NewChaosHandler(deps.ChaosConfig, deps.Broadcaster)
 	conformanceHandler := NewConformanceHandler()
+	cronHandler := NewCronHandler(deps.CronRegistry, deps.Store)
+	dlqHandler := NewDLQHandler(deps.MemoryBackend)
+	workflowHandler := NewWorkflowHandler(deps.MemoryBackend)
+	snapshotHandler := NewSnapshotHandler(deps.SnapshotStore)
+	validateHandler := NewValidateHandler(deps.Validator)
+	schemaHandler := NewSchemaHandler(deps.SchemaRegistry)
 	sseHandler := sse.NewHandler(deps.Broadcaster)
 
 	r.Route("/api", func(r chi.Router) {
 		// Health
 		r.Get("/health", healthHandler.Health)
 
-		// Jobs
-		r.Post("/jobs", jobHandler.Create)
+		// Jobs, checked against the OJS job schema as on the OJS routes
+		r.Group(func(r chi.Router) {
+			if deps.Validator != nil {
+				r.Use(deps.Validator.Middleware)
+			}
+			r.Post("/jobs", jobHandler.Create)
+			r.Post("/jobs/batch", jobHandler.Batch)
+		})
 		r.Get("/jobs", jobHandler.List)
 		r.Get("/jobs/{id}", jobHandler.Get)
 		r.Delete("/jobs/{id}", jobHandler.Cancel)
@@ -54,12 +72,43 @@ func RegisterRoutes(r chi.Router, deps *RouteDeps) {
 		r.Post("/workers", workerHandler.Register)
 		r.Delete("/workers/{id}", workerHandler.Delete)
 		r.Post("/workers/{id}/drain", workerHandler.Drain)
+		r.Get("/workers/{id}/jobs", workerHandler.Jobs)
 
 		// Chaos
 		r.Get("/chaos", chaosHandler.Get)
 		r.Put("/chaos", chaosHandler.Update)
 		r.Delete("/chaos", chaosHandler.Reset)
 
+		// Dead letter queue
+		r.Get("/dlq", dlqHandler.List)
+		r.Delete("/dlq", dlqHandler.Purge)
+		r.Post("/dlq/replay", dlqHandler.ReplayBulk)
+		r.Get("/dlq/{id}", dlqHandler.Get)
+		r.Delete("/dlq/{id}", dlqHandler.Delete)
+		r.Post("/dlq/{id}/replay", dlqHandler.Replay)
+
+		// Workflows
+		r.Get("/workflows", workflowHandler.List)
+		r.Post("/workflows", workflowHandler.Create)
+		r.Get("/workflows/{id}", workflowHandler.Get)
+
+		// Snapshots
+		r.Get("/snapshots", snapshotHandler.List)
+		r.Post("/snapshots", snapshotHandler.Save)
+		r.Post("/snapshots/{name}/load", snapshotHandler.Load)
+
+		// Validation
+		r.Post("/validate", validateHandler.Validate)
+
+		// Job args schemas
+		r.Get("/schemas", schemaHandler.List)
+		r.Post("/schemas", schemaHandler.Register)
+		r.Get("/schemas/{uri}", schemaHandler.Get)
+		r.Delete("/schemas/{uri}", schemaHandler.Delete)
+
+		// Cron
+		r.Route("/cron", cronHandler.Routes)
+
 		// Conformance
 		r.Post("/conformance/run", conformanceHandler.Run)
 		r.Get("/conformance/run/{id}", conformanceHandler.GetRun)
@@ -68,4 +117,7 @@ func RegisterRoutes(r chi.Router, deps *RouteDeps) {
 		// SSE events
 		r.Get("/events", sseHandler.ServeHTTP)
 	})
+
+	// OJS cron endpoints are served by the playground for every backend.
+	r.Route("/ojs/v1/cron", cronHandler.Routes)
 }
@@ -5,7 +5,7 @@ import "context"
 // BackendAdapter is the interface for backend implementations.
 type BackendAdapter interface {
 	Name() string
-	Type() string // "memory", "redis", "postgres"
+	Type() string // "memory", "redis", "sqlite", "postgres", "http"
 	URL() string
 	Health(ctx context.Context) (*HealthStatus, error)
 	Stats(ctx context.Context) (*BackendStats, error)
@@ -24,4 +24,67 @@ type BackendStats struct {
 	ActiveJobs   int            `json:"active_jobs"`
 	QueueDepths  map[string]int `json:"queue_depths,omitempty"`
 	WorkerCount  int            `json:"worker_count"`
+	Paused       bool           `json:"paused"`
+	PausedQueues []string       `json:"paused_queues,omitempty"`
+
+	// RateLimitHits counts jobs held back by each rate limit, keyed by
+	// "queue:<name>" or "key:<rate limit key>".
+	RateLimitHits map[string]int `json:"rate_limit_hits,omitempty"`
+}
+
+// Pausable is implemented by backends whose queues can be paused.
+// A paused queue keeps accepting jobs but hands none out to workers.
+type Pausable interface {
+	Pause()
+	Resume()
+	PauseQueue(name string)
+	ResumeQueue(name string)
+}
+
+// Enqueuer is implemented by job backends that report whether an enqueue
+// stored a new job or returned an existing one, as a unique policy with
+// on_conflict "ignore" does.
+type Enqueuer interface {
+	EnqueueContext(ctx context.Context, req *EnqueueRequest) (job *MemoryJob, created bool, err error)
+}
+
+// Batcher is implemented by job backends that can enqueue several jobs at
+// once.
+type Batcher interface {
+	EnqueueBatch(req *BatchRequest) (*BatchResponse, error)
+}
+
+// JobBackend is the companion to BackendAdapter for backends the
+// playground can run jobs through, in-process or over OJS HTTP. Errors the
+// backend reports are *RequestError; any other error means the backend
+// could not be reached.
+type JobBackend interface {
+	EnqueueJob(ctx context.Context, req *EnqueueRequest) (*MemoryJob, error)
+	Job(ctx context.Context, id string) (*MemoryJob, error)
+	Jobs(ctx context.Context, filter JobFilter) ([]*MemoryJob, int, error)
+	CancelJob(ctx context.Context, id string) (*MemoryJob, error)
+	RetryJob(ctx context.Context, id string) (*MemoryJob, error)
+
+	Fetch(ctx context.Context, req FetchRequest) ([]*MemoryJob, error)
+	Ack(ctx context.Context, req AckRequest) (*MemoryJob, error)
+	Nack(ctx context.Context, req NackRequest) (*MemoryJob, error)
+
+	Queues(ctx context.Context) ([]QueueInfo, error)
+	SetQueuePaused(ctx context.Context, name string, paused bool) error
+}
+
+// JobFilter selects jobs for JobBackend.Jobs. Empty fields match any job.
+type JobFilter struct {
+	State  string
+	Type   string
+	Queue  string
+	Limit  int
+	Offset int
+}
+
+// QueueInfo describes a queue.
+type QueueInfo struct {
+	Name      string `json:"name"`
+	Available int    `json:"available"`
+	Paused    bool   `json:"paused"`
 }
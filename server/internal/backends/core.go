@@ -0,0 +1,248 @@
+package backends
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// coreBackend is a persistent backend that implements the OJS Level 0
+// operations, and none of MemoryBackend's extensions.
+type coreBackend interface {
+	BackendAdapter
+	JobBackend
+	Heartbeat(ctx context.Context, req HeartbeatRequest) (*MemoryJob, error)
+}
+
+// coreRouter returns a router serving b on the same routes as
+// MemoryBackend.Router. Routes of the extensions b lacks answer 501.
+func coreRouter(b coreBackend) chi.Router {
+	r := chi.NewRouter()
+	h := coreHandlers{b}
+
+	r.Get("/health", h.health)
+	r.Get("/jobs", h.listJobs)
+	r.Post("/jobs", h.createJob)
+	r.Post("/jobs/batch", h.unsupported)
+	r.Get("/jobs/{id}", h.getJob)
+	r.Post("/jobs/{id}/progress", h.unsupported)
+	r.Delete("/jobs/{id}", h.cancelJob)
+	r.Post("/workers/fetch", h.fetch)
+	r.Post("/workers/ack", h.ack)
+	r.Post("/workers/nack", h.nack)
+	r.Post("/workers/heartbeat", h.heartbeat)
+	r.Get("/queues", h.listQueues)
+	r.Post("/queues/{name}/pause", h.pauseQueue)
+	r.Post("/queues/{name}/resume", h.resumeQueue)
+	r.Get("/queues/{name}/config", h.unsupported)
+	r.Put("/queues/{name}/config", h.unsupported)
+	r.Get("/dead", h.unsupported)
+	r.Post("/dead/retry", h.unsupported)
+	r.Delete("/dead", h.unsupported)
+	r.Get("/dead/{id}", h.unsupported)
+	r.Post("/dead/{id}/retry", h.retryDead)
+	r.Delete("/dead/{id}", h.unsupported)
+	r.Post("/workflows", h.unsupported)
+	r.Get("/workflows/{id}", h.unsupported)
+
+	return r
+}
+
+// checkCoreOptions rejects enqueue options that only MemoryBackend
+// supports.
+func checkCoreOptions(job *MemoryJob, backend string) error {
+	var errs fieldErrors
+	if job.Unique != nil {
+		errs.add("options.unique", fmt.Errorf("is not supported by the %s backend", backend))
+	}
+	if job.RateLimit != nil {
+		errs.add("options.rate_limit", fmt.Errorf("is not supported by the %s backend", backend))
+	}
+	if job.ExpiresAt != "" {
+		errs.add("options.expires_at", fmt.Errorf("is not supported by the %s backend", backend))
+	}
+	if job.Retry != nil && job.Retry.OnExhaustion == OnExhaustionDeadLetter {
+		errs.add("options.retry.on_exhaustion", fmt.Errorf("%q is not supported by the %s backend", OnExhaustionDeadLetter, backend))
+	}
+	return errs.err()
+}
+
+// statesAllowing returns the states a job may move to state from.
+func statesAllowing(state string) []string {
+	var from []string
+	for s := range validTransitions {
+		if isValidTransition(s, state) {
+			from = append(from, s)
+		}
+	}
+	return from
+}
+
+// coreHandlers adapts a coreBackend to OJS HTTP.
+type coreHandlers struct {
+	b coreBackend
+}
+
+func (h coreHandlers) health(w http.ResponseWriter, r *http.Request) {
+	status, err := h.b.Health(r.Context())
+	if err != nil {
+		status = &HealthStatus{Status: "error", Message: err.Error()}
+	}
+	code := http.StatusOK
+	if status.Status != "ok" {
+		code = http.StatusServiceUnavailable
+	}
+	body := map[string]any{"status": status.Status, "backend": h.b.Type()}
+	if status.Message != "" {
+		body["message"] = status.Message
+	}
+	writeJSON(w, code, body)
+}
+
+func (h coreHandlers) listJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := JobFilter{State: query.Get("state"), Type: query.Get("type"), Queue: query.Get("queue")}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		filter.Limit, _ = strconv.Atoi(limitStr)
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		filter.Offset, _ = strconv.Atoi(offsetStr)
+	}
+
+	jobs, total, err := h.b.Jobs(r.Context(), filter)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	if jobs == nil {
+		jobs = []*MemoryJob{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"jobs": jobs, "total": total})
+}
+
+func (h coreHandlers) createJob(w http.ResponseWriter, r *http.Request) {
+	var req EnqueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeRequestError(w, decodeError(err))
+		return
+	}
+
+	job, err := h.b.EnqueueJob(r.Context(), &req)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/ojs/v1/jobs/"+job.ID)
+	writeJSON(w, http.StatusCreated, map[string]any{"job": job})
+}
+
+func (h coreHandlers) getJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.b.Job(r.Context(), chi.URLParam(r, "id"))
+	writeJobResult(w, job, err)
+}
+
+func (h coreHandlers) cancelJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.b.CancelJob(r.Context(), chi.URLParam(r, "id"))
+	writeJobResult(w, job, err)
+}
+
+func (h coreHandlers) retryDead(w http.ResponseWriter, r *http.Request) {
+	job, err := h.b.RetryJob(r.Context(), chi.URLParam(r, "id"))
+	writeJobResult(w, job, err)
+}
+
+func (h coreHandlers) fetch(w http.ResponseWriter, r *http.Request) {
+	var req FetchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
+		return
+	}
+
+	fetched, err := h.b.Fetch(r.Context(), req)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	if fetched == nil {
+		fetched = []*MemoryJob{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"jobs": fetched})
+}
+
+func (h coreHandlers) ack(w http.ResponseWriter, r *http.Request) {
+	var req AckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
+		return
+	}
+	job, err := h.b.Ack(r.Context(), req)
+	writeJobResult(w, job, err)
+}
+
+func (h coreHandlers) nack(w http.ResponseWriter, r *http.Request) {
+	var req NackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
+		return
+	}
+	job, err := h.b.Nack(r.Context(), req)
+	writeJobResult(w, job, err)
+}
+
+func (h coreHandlers) heartbeat(w http.ResponseWriter, r *http.Request) {
+	var req HeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
+		return
+	}
+	job, err := h.b.Heartbeat(r.Context(), req)
+	writeJobResult(w, job, err)
+}
+
+func (h coreHandlers) listQueues(w http.ResponseWriter, r *http.Request) {
+	queues, err := h.b.Queues(r.Context())
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	if queues == nil {
+		queues = []QueueInfo{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"queues": queues})
+}
+
+func (h coreHandlers) pauseQueue(w http.ResponseWriter, r *http.Request) {
+	h.setQueuePaused(w, r, true)
+}
+
+func (h coreHandlers) resumeQueue(w http.ResponseWriter, r *http.Request) {
+	h.setQueuePaused(w, r, false)
+}
+
+func (h coreHandlers) setQueuePaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	name := chi.URLParam(r, "name")
+	if err := h.b.SetQueuePaused(r.Context(), name, paused); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"queue": map[string]any{"name": name, "paused": paused}})
+}
+
+func (h coreHandlers) unsupported(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotImplemented, "unsupported",
+		fmt.Sprintf("The %s backend does not support %s %s.", h.b.Type(), r.Method, r.URL.Path))
+}
+
+// writeJobResult writes the outcome of an operation on one job.
+func writeJobResult(w http.ResponseWriter, job *MemoryJob, err error) {
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"job": job})
+}
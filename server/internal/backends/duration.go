@@ -0,0 +1,34 @@
+package backends
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var durationRE = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseDuration parses an ISO 8601 duration such as PT1S, PT5M or P1D.
+// Years and months are approximated as 365 and 30 days, matching the UI engine.
+func parseDuration(iso string) (time.Duration, error) {
+	match := durationRE.FindStringSubmatch(iso)
+	if match == nil || iso == "P" || iso[len(iso)-1] == 'T' {
+		return 0, fmt.Errorf("invalid ISO 8601 duration: %s", iso)
+	}
+
+	units := []float64{365 * 86400, 30 * 86400, 7 * 86400, 86400, 3600, 60, 1}
+	var seconds float64
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(match[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO 8601 duration: %s", iso)
+		}
+		seconds += n * unit
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
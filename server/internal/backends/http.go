@@ -0,0 +1,106 @@
+package backends
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// HTTPBackend is an external OJS server, reached at its base URL. Any
+// server that implements the OJS HTTP binding can be plugged in; job
+// operations go through its embedded OJSClient.
+type HTTPBackend struct {
+	*OJSClient
+	name string
+	url  string
+}
+
+// NewHTTPBackend creates an adapter for the OJS server at baseURL, the URL
+// the /ojs/v1 endpoints are served under. State changes caused through the
+// adapter are reported to onStateChange.
+func NewHTTPBackend(name, baseURL string, onStateChange StateChangeCallback) (*HTTPBackend, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("base URL %q must be an absolute http or https URL", baseURL)
+	}
+	return &HTTPBackend{
+		OJSClient: NewOJSClient(baseURL, onStateChange),
+		name:      name,
+		url:       baseURL,
+	}, nil
+}
+
+// Name returns the name the backend was configured with.
+func (b *HTTPBackend) Name() string { return b.name }
+
+// Type returns "http".
+func (b *HTTPBackend) Type() string { return "http" }
+
+// URL returns the server's base URL.
+func (b *HTTPBackend) URL() string { return b.url }
+
+// Health reports the status from GET /ojs/v1/health. A server that answers
+// with an error status is reported as an error rather than unreachable.
+func (b *HTTPBackend) Health(ctx context.Context) (*HealthStatus, error) {
+	var resp struct {
+		Status string `json:"status"`
+	}
+	err := b.do(ctx, http.MethodGet, "/ojs/v1/health", nil, &resp)
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return &HealthStatus{Status: "error", Message: reqErr.Message}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status == "" {
+		resp.Status = "ok"
+	}
+	return &HealthStatus{Status: resp.Status}, nil
+}
+
+// Stats reports the queue depths and paused queues from GET /ojs/v1/queues.
+// Servers that list each queue's stats there also give the active job
+// count; the other totals are not available over OJS.
+func (b *HTTPBackend) Stats(ctx context.Context) (*BackendStats, error) {
+	var resp struct {
+		Queues []struct {
+			QueueInfo
+			Status string `json:"status"`
+			Stats  *struct {
+				Available int `json:"available"`
+				Active    int `json:"active"`
+			} `json:"stats"`
+		} `json:"queues"`
+	}
+	if err := b.do(ctx, http.MethodGet, "/ojs/v1/queues", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	stats := &BackendStats{QueueDepths: make(map[string]int)}
+	for _, q := range resp.Queues {
+		depth := q.Available
+		if q.Stats != nil {
+			depth = q.Stats.Available
+			stats.ActiveJobs += q.Stats.Active
+		}
+		stats.QueueDepths[q.Name] = depth
+		if q.Paused || q.Status == "paused" {
+			stats.PausedQueues = append(stats.PausedQueues, q.Name)
+		}
+	}
+	sort.Strings(stats.PausedQueues)
+	return stats, nil
+}
+
+// Close releases idle connections to the server.
+func (b *HTTPBackend) Close() error {
+	b.http.CloseIdleConnections()
+	return nil
+}
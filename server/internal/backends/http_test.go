@@ -0,0 +1,60 @@
+package backends
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPBackend(t *testing.T) {
+	mb := newTestBackend()
+	srv := httptest.NewServer(http.StripPrefix("/ojs/v1", mb.Router()))
+	defer srv.Close()
+
+	b, err := NewHTTPBackend("remote", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if h, err := b.Health(ctx); err != nil || h.Status != "ok" {
+		t.Errorf("expected ok, got %+v %v", h, err)
+	}
+
+	mb.Enqueue(&EnqueueRequest{Type: "a", Args: json.RawMessage(`[]`), Options: &EnqueueOptions{Queue: "q"}})
+	mb.Enqueue(&EnqueueRequest{Type: "a", Args: json.RawMessage(`[]`), Options: &EnqueueOptions{Queue: "q"}})
+	mb.PauseQueue("idle")
+	stats, err := b.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.QueueDepths["q"] != 2 || len(stats.PausedQueues) != 1 || stats.PausedQueues[0] != "idle" {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// Servers that report per-queue stats and status.
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ojs/v1/health":
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "error"})
+		case "/ojs/v1/queues":
+			w.Write([]byte(`{"queues": [{"name": "default", "status": "paused", "stats": {"available": 3, "active": 1}}]}`))
+		}
+	}))
+	defer other.Close()
+
+	b, _ = NewHTTPBackend("other", other.URL, nil)
+	if h, err := b.Health(ctx); err != nil || h.Status != "error" {
+		t.Errorf("expected an error status, got %+v %v", h, err)
+	}
+	stats, err = b.Stats(ctx)
+	if err != nil || stats.QueueDepths["default"] != 3 || stats.ActiveJobs != 1 || len(stats.PausedQueues) != 1 {
+		t.Errorf("unexpected stats %+v %v", stats, err)
+	}
+
+	if _, err := NewHTTPBackend("bad", "localhost:8080", nil); err == nil {
+		t.Error("expected a URL without a scheme to be rejected")
+	}
+}
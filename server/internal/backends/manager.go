@@ -40,6 +40,19 @@ func (m *Manager) Active() (BackendAdapter, error) {
 	return b, nil
 }
 
+// ActiveJobs returns the active backend's job operations.
+func (m *Manager) ActiveJobs() (JobBackend, error) {
+	b, err := m.Active()
+	if err != nil {
+		return nil, err
+	}
+	jobs, ok := b.(JobBackend)
+	if !ok {
+		return nil, fmt.Errorf("backend %q does not support job operations", b.Name())
+	}
+	return jobs, nil
+}
+
 // ActiveName returns the name of the active backend.
 func (m *Manager) ActiveName() string {
 	m.mu.RLock()
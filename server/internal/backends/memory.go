@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+
+	"github.com/openjobspec/ojs-playground/server/internal/chaos"
+	"github.com/openjobspec/ojs-playground/server/internal/sse"
 )
 
 // Job states as defined by the OJS specification.
@@ -55,45 +58,107 @@ func isTerminalState(state string) bool {
 
 // MemoryJob is the in-memory representation of a job.
 type MemoryJob struct {
-	ID          string          `json:"id"`
-	Type        string          `json:"type"`
-	State       string          `json:"state"`
-	Queue       string          `json:"queue"`
-	Args        json.RawMessage `json:"args"`
-	Meta        json.RawMessage `json:"meta,omitempty"`
-	Priority    int             `json:"priority"`
-	Attempt     int             `json:"attempt"`
-	MaxAttempts int             `json:"max_attempts"`
-	TimeoutMs   *int            `json:"timeout_ms,omitempty"`
-	CreatedAt   string          `json:"created_at"`
-	EnqueuedAt  string          `json:"enqueued_at,omitempty"`
-	StartedAt   string          `json:"started_at,omitempty"`
-	CompletedAt string          `json:"completed_at,omitempty"`
-	CancelledAt string          `json:"cancelled_at,omitempty"`
-	ScheduledAt string          `json:"scheduled_at,omitempty"`
-	Result      json.RawMessage `json:"result,omitempty"`
-	Error       json.RawMessage `json:"error,omitempty"`
-	Tags        []string        `json:"tags,omitempty"`
-}
-
-// StateChangeCallback is called when a job state changes.
-type StateChangeCallback func(job *MemoryJob, fromState, toState string)
+	SpecVersion    string           `json:"specversion"`
+	ID             string           `json:"id"`
+	Type           string           `json:"type"`
+	State          string           `json:"state"`
+	Queue          string           `json:"queue"`
+	Args           json.RawMessage  `json:"args"`
+	Meta           json.RawMessage  `json:"meta,omitempty"`
+	Schema         string           `json:"schema,omitempty"`
+	Priority       int              `json:"priority"`
+	Attempt        int              `json:"attempt"`
+	MaxAttempts    int              `json:"max_attempts"`
+	TimeoutMs      *int             `json:"timeout_ms,omitempty"`
+	CreatedAt      string           `json:"created_at"`
+	EnqueuedAt     string           `json:"enqueued_at,omitempty"`
+	StartedAt      string           `json:"started_at,omitempty"`
+	CompletedAt    string           `json:"completed_at,omitempty"`
+	CancelledAt    string           `json:"cancelled_at,omitempty"`
+	DiscardedAt    string           `json:"discarded_at,omitempty"`
+	ScheduledAt    string           `json:"scheduled_at,omitempty"`
+	ExpiresAt      string           `json:"expires_at,omitempty"`
+	Result         json.RawMessage  `json:"result,omitempty"`
+	Error          json.RawMessage  `json:"error,omitempty"`
+	Progress       *ProgressUpdate  `json:"progress,omitempty"`
+	Tags           []string         `json:"tags,omitempty"`
+	Retry          *RetryPolicy     `json:"retry,omitempty"`
+	Timeout        *TimeoutPolicy   `json:"timeout,omitempty"`
+	Unique         *UniquePolicy    `json:"unique,omitempty"`
+	RateLimit      *RateLimitPolicy `json:"rate_limit,omitempty"`
+	UniqueKey      string           `json:"unique_key,omitempty"`
+	NextAttemptAt  string           `json:"next_attempt_at,omitempty"`
+	LeaseExpiresAt string           `json:"lease_expires_at,omitempty"`
+	WorkerID       string           `json:"worker_id,omitempty"`
+	WorkflowID     string           `json:"workflow_id,omitempty"`
+	ParentID       string           `json:"parent_id,omitempty"`
+}
+
+// clone returns a copy of the job that is safe to use without m.mu. Fields
+// holding pointers or slices are replaced, never changed in place, so they
+// are shared.
+func (j *MemoryJob) clone() *MemoryJob {
+	c := *j
+	return &c
+}
+
+// StateChangeCallback is called when a job state changes. Reason is set
+// when the change was not asked for, such as a job expiring.
+type StateChangeCallback func(job *MemoryJob, fromState, toState, reason string)
 
 // MemoryBackend implements a full Level 0 OJS backend in memory.
 type MemoryBackend struct {
-	mu              sync.RWMutex
-	jobs            map[string]*MemoryJob
-	queues          map[string][]*MemoryJob // queue name → available jobs (sorted by priority)
-	onStateChange   StateChangeCallback
+	mu            sync.RWMutex
+	jobs          map[string]*MemoryJob
+	queues        map[string]*jobQueue   // queue name → available jobs
+	delayed       map[string]time.Time   // scheduled or retryable job ID → time it becomes available
+	expiring      map[string]time.Time   // unstarted job ID → time it expires
+	finished      map[string]time.Time   // finished job ID → time it finished
+	leases        map[string]*lease      // active job ID → lease
+	unique        map[string]uniqueEntry // unique fingerprint → latest job holding it
+	pausedQueues  map[string]bool
+	queueConfigs  map[string]*QueueConfig
+	dead          map[string]*DeadLetter  // job ID → dead letter
+	workflows     map[string]*Workflow    // workflow ID → workflow
+	rateLimiters  map[string]*rateLimiter // "queue:<name>" or "key:<key>" → limiter state
+	rateLimitHits map[string]int          // limiter name → jobs it held back
+	paused        bool                    // every queue paused
+	chaos         *chaos.Config
+	broadcaster   *sse.Broadcaster
+	onStateChange StateChangeCallback
+	onProgress    ProgressCallback
+	validateArgs  ArgsValidator
+	waiters       []*fetchWaiter // fetches waiting for jobs, longest waiting first
+	cancel        context.CancelFunc
+
+	changeMu sync.Mutex
+	changeCh chan struct{} // closed and replaced whenever job state changes
 }
 
-// NewMemoryBackend creates a new in-memory backend.
+// NewMemoryBackend creates a new in-memory backend and starts its
+// background scheduler. Call Close to stop it.
 func NewMemoryBackend(onStateChange StateChangeCallback) *MemoryBackend {
-	return &MemoryBackend{
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &MemoryBackend{
 		jobs:          make(map[string]*MemoryJob),
-		queues:        make(map[string][]*MemoryJob),
+		queues:        make(map[string]*jobQueue),
+		delayed:       make(map[string]time.Time),
+		expiring:      make(map[string]time.Time),
+		finished:      make(map[string]time.Time),
+		leases:        make(map[string]*lease),
+		unique:        make(map[string]uniqueEntry),
+		pausedQueues:  make(map[string]bool),
+		queueConfigs:  make(map[string]*QueueConfig),
+		dead:          make(map[string]*DeadLetter),
+		workflows:     make(map[string]*Workflow),
+		rateLimiters:  make(map[string]*rateLimiter),
+		rateLimitHits: make(map[string]int),
 		onStateChange: onStateChange,
+		cancel:        cancel,
+		changeCh:      make(chan struct{}),
 	}
+	go m.runScheduler(ctx)
+	return m
 }
 
 // Name returns the backend name.
@@ -120,21 +185,44 @@ func (m *MemoryBackend) Stats(ctx context.Context) (*BackendStats, error) {
 		QueueDepths: make(map[string]int),
 	}
 
+	workers := make(map[string]bool)
 	for _, j := range m.jobs {
 		if j.State == StateActive {
 			stats.ActiveJobs++
+			if j.WorkerID != "" {
+				workers[j.WorkerID] = true
+			}
 		}
 	}
+	for _, w := range m.waiters {
+		if w.workerID != "" {
+			workers[w.workerID] = true
+		}
+	}
+	stats.WorkerCount = len(workers)
+
+	for name, q := range m.queues {
+		stats.QueueDepths[name] = q.Len()
+	}
+
+	stats.Paused = m.paused
+	stats.PausedQueues = m.pausedQueueNames()
 
-	for q, jobs := range m.queues {
-		stats.QueueDepths[q] = len(jobs)
+	if len(m.rateLimitHits) > 0 {
+		stats.RateLimitHits = make(map[string]int, len(m.rateLimitHits))
+		for name, n := range m.rateLimitHits {
+			stats.RateLimitHits[name] = n
+		}
 	}
 
 	return stats, nil
 }
 
-// Close is a no-op for in-memory backend.
-func (m *MemoryBackend) Close() error { return nil }
+// Close stops the background scheduler.
+func (m *MemoryBackend) Close() error {
+	m.cancel()
+	return nil
+}
 
 // Router returns a chi router implementing OJS HTTP endpoints.
 // Routes are relative (no /ojs/v1 prefix) — mount at /ojs/v1.
@@ -142,19 +230,39 @@ func (m *MemoryBackend) Router() chi.Router {
 	r := chi.NewRouter()
 
 	r.Get("/health", m.handleHealth)
+	r.Get("/jobs", m.handleListJobs)
 	r.Post("/jobs", m.handleCreateJob)
+	r.Post("/jobs/batch", m.handleBatchCreate)
 	r.Get("/jobs/{id}", m.handleGetJob)
+	r.Post("/jobs/{id}/progress", m.handleProgress)
 	r.Delete("/jobs/{id}", m.handleCancelJob)
 	r.Post("/workers/fetch", m.handleFetch)
 	r.Post("/workers/ack", m.handleAck)
 	r.Post("/workers/nack", m.handleNack)
+	r.Post("/workers/heartbeat", m.handleHeartbeat)
 	r.Get("/queues", m.handleListQueues)
+	r.Post("/queues/{name}/pause", m.handlePauseQueue)
+	r.Post("/queues/{name}/resume", m.handleResumeQueue)
+	r.Get("/queues/{name}/config", m.handleGetQueueConfig)
+	r.Put("/queues/{name}/config", m.handlePutQueueConfig)
+	r.Get("/dead", m.handleListDead)
+	r.Post("/dead/retry", m.handleReplayDeadBulk)
+	r.Delete("/dead", m.handlePurgeDead)
+	r.Get("/dead/{id}", m.handleGetDead)
+	r.Post("/dead/{id}/retry", m.handleReplayDead)
+	r.Delete("/dead/{id}", m.handlePurgeDead)
+	r.Post("/workflows", m.handleCreateWorkflow)
+	r.Get("/workflows/{id}", m.handleGetWorkflow)
 
 	return r
 }
 
 func nowFormatted() string {
-	return time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+	return formatTime(time.Now())
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
 }
 
 func writeJSON(w http.ResponseWriter, status int, data any) {
@@ -171,38 +279,174 @@ func writeError(w http.ResponseWriter, status int, code, message string) {
 
 func (m *MemoryBackend) handleHealth(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{
-		"status": "ok",
+		"status":  "ok",
 		"backend": "memory",
 	})
 }
 
-func (m *MemoryBackend) handleCreateJob(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		ID      string          `json:"id,omitempty"`
-		Type    string          `json:"type"`
-		Args    json.RawMessage `json:"args"`
-		Meta    json.RawMessage `json:"meta,omitempty"`
-		Options *struct {
-			Queue       string `json:"queue,omitempty"`
-			Priority    *int   `json:"priority,omitempty"`
-			TimeoutMs   *int   `json:"timeout_ms,omitempty"`
-			ScheduledAt string `json:"scheduled_at,omitempty"`
-			Tags        []string `json:"tags,omitempty"`
-		} `json:"options,omitempty"`
-	}
+// EnqueueRequest is the body of POST /jobs.
+type EnqueueRequest struct {
+	SpecVersion string          `json:"specversion,omitempty"`
+	ID          string          `json:"id,omitempty"`
+	Type        string          `json:"type"`
+	Args        json.RawMessage `json:"args"`
+	Meta        json.RawMessage `json:"meta,omitempty"`
+	Schema      string          `json:"schema,omitempty"`
+	Options     *EnqueueOptions `json:"options,omitempty"`
+}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
+// EnqueueOptions are the optional enqueue settings of an EnqueueRequest.
+type EnqueueOptions struct {
+	Queue       string              `json:"queue,omitempty"`
+	Priority    *int                `json:"priority,omitempty"`
+	TimeoutMs   *int                `json:"timeout_ms,omitempty"`
+	ScheduledAt string              `json:"scheduled_at,omitempty"`
+	ExpiresAt   string              `json:"expires_at,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Retry       *retryPolicyRequest `json:"retry,omitempty"`
+	Timeout     *TimeoutPolicy      `json:"timeout,omitempty"`
+	Unique      *UniquePolicy       `json:"unique,omitempty"`
+	RateLimit   *RateLimitPolicy    `json:"rate_limit,omitempty"`
+
+	unknown []string // option names that are not part of OJS
+}
+
+// RequestError is an OJS error returned by a backend operation, carrying
+// the HTTP status and error code to report.
+type RequestError struct {
+	Status  int
+	Code    string
+	Message string
+	Details map[string]any
+}
+
+func (e *RequestError) Error() string { return e.Message }
+
+func validationError(message string) *RequestError {
+	return &RequestError{Status: http.StatusUnprocessableEntity, Code: "validation_error", Message: message}
+}
+
+// writeRequestError writes err as an OJS error response. Errors that are
+// not a *RequestError are reported as internal errors.
+func writeRequestError(w http.ResponseWriter, err error) {
+	reqErr, ok := err.(*RequestError)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "internal_error", err.Error())
 		return
 	}
+	body := map[string]any{"code": reqErr.Code, "message": reqErr.Message}
+	for k, v := range reqErr.Details {
+		body[k] = v
+	}
+	writeJSON(w, reqErr.Status, map[string]any{"error": body})
+}
+
+// Enqueue validates and stores a new job. It returns created=false with the
+// existing job when a unique policy with on_conflict "ignore" matched.
+func (m *MemoryBackend) Enqueue(req *EnqueueRequest) (*MemoryJob, bool, error) {
+	return m.EnqueueContext(context.Background(), req)
+}
+
+// EnqueueContext is like Enqueue, but when the job's queue is full and has
+// the "block" overflow policy it waits for room until ctx is done or
+// maxBlockWait has passed.
+func (m *MemoryBackend) EnqueueContext(ctx context.Context, req *EnqueueRequest) (*MemoryJob, bool, error) {
+	job, runAt, err := m.prepareJob(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	timeout := time.NewTimer(maxBlockWait)
+	defer timeout.Stop()
+	for {
+		m.mu.Lock()
+		stored, created, changes, err := m.insertJob(job, runAt, true)
+		var changed <-chan struct{}
+		if isQueueFull(err) && m.blocksWhenFull(job.Queue) {
+			changed = m.changed()
+		}
+		if stored != nil {
+			stored = stored.clone()
+		}
+		m.mu.Unlock()
+
+		if changed == nil {
+			m.notify(changes)
+			return stored, created, err
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, false, err
+		case <-timeout.C:
+			return nil, false, err
+		}
+	}
+}
 
+// prepareJob validates an enqueue request and builds the job it describes,
+// along with the time it becomes available if it is scheduled.
+func (m *MemoryBackend) prepareJob(req *EnqueueRequest) (*MemoryJob, time.Time, error) {
+	m.mu.RLock()
+	validateArgs := m.validateArgs
+	m.mu.RUnlock()
+	return buildJob(req, m.QueueConfig, validateArgs)
+}
+
+// buildJob validates an enqueue request against the configuration of its
+// queue and builds the job it describes, along with the time it becomes
+// available if it is scheduled. Every invalid field is reported, not just
+// the first.
+func buildJob(req *EnqueueRequest, queueConfig func(name string) QueueConfig, validateArgs ArgsValidator) (*MemoryJob, time.Time, error) {
+	var errs fieldErrors
+	if req.SpecVersion != "" && req.SpecVersion != SpecVersion {
+		errs.add("specversion", fmt.Errorf("must be %q", SpecVersion))
+	}
 	if req.Type == "" {
-		writeError(w, http.StatusUnprocessableEntity, "validation_error", "Field 'type' is required.")
-		return
+		errs.add("type", fmt.Errorf("is required"))
 	}
 
 	if req.Args == nil {
 		req.Args = json.RawMessage(`[]`)
+	} else if !isJSONKind(req.Args, '[') {
+		errs.add("args", fmt.Errorf("must be an array"))
+	} else if req.Type != "" {
+		checkArgs(&errs, req, validateArgs)
+	}
+	if req.Meta != nil && !isJSONKind(req.Meta, '{') {
+		errs.add("meta", fmt.Errorf("must be an object"))
+	}
+
+	opts := req.Options
+	if opts == nil {
+		opts = &EnqueueOptions{}
+	}
+	for _, name := range opts.unknown {
+		errs.add("options."+name, fmt.Errorf("is not a known option"))
+	}
+
+	queue := "default"
+	if opts.Queue != "" {
+		queue = opts.Queue
+	}
+	cfg := queueConfig(queue)
+	if req.Type != "" && !cfg.allowsType(req.Type) {
+		errs.add("type", fmt.Errorf("%q is not allowed on queue %q", req.Type, queue))
+	}
+
+	retryReq := opts.Retry
+	if retryReq == nil {
+		retryReq = cfg.DefaultRetry
+	}
+	retry, err := retryReq.resolve()
+	if err != nil {
+		errs.add("options", err)
+		retry = &RetryPolicy{}
+	}
+	// A queue with a dead letter queue dead-letters its exhausted jobs,
+	// unless their policy says otherwise.
+	if cfg.DeadLetterQueue != "" && (retryReq == nil || retryReq.OnExhaustion == "") {
+		retry.OnExhaustion = OnExhaustionDeadLetter
 	}
 
 	id := req.ID
@@ -213,292 +457,325 @@ func (m *MemoryBackend) handleCreateJob(w http.ResponseWriter, r *http.Request)
 
 	now := nowFormatted()
 	job := &MemoryJob{
+		SpecVersion: SpecVersion,
 		ID:          id,
 		Type:        req.Type,
 		State:       StateAvailable,
-		Queue:       "default",
+		Queue:       queue,
 		Args:        req.Args,
 		Meta:        req.Meta,
+		Schema:      req.Schema,
 		Priority:    0,
 		Attempt:     0,
-		MaxAttempts: 3,
+		MaxAttempts: retry.MaxAttempts,
 		CreatedAt:   now,
 		EnqueuedAt:  now,
+		Retry:       retry,
 	}
 
-	if req.Options != nil {
-		if req.Options.Queue != "" {
-			job.Queue = req.Options.Queue
+	if opts.Priority != nil {
+		job.Priority = *opts.Priority
+	}
+	if opts.TimeoutMs != nil {
+		if *opts.TimeoutMs < 0 {
+			errs.add("options.timeout_ms", fmt.Errorf("must not be negative"))
+		}
+		job.TimeoutMs = opts.TimeoutMs
+	}
+	if opts.Tags != nil {
+		job.Tags = opts.Tags
+	}
+	if opts.Timeout != nil {
+		if err := opts.Timeout.validate(); err != nil {
+			errs.add("options", err)
 		}
-		if req.Options.Priority != nil {
-			job.Priority = *req.Options.Priority
+		job.Timeout = opts.Timeout
+	}
+	if job.Timeout == nil && job.TimeoutMs == nil && cfg.DefaultTimeout > 0 {
+		job.Timeout = &TimeoutPolicy{Execution: cfg.DefaultTimeout}
+	}
+	if opts.RateLimit != nil {
+		if err := opts.RateLimit.normalize(true); err != nil {
+			errs.add("options", err)
 		}
-		if req.Options.TimeoutMs != nil {
-			job.TimeoutMs = req.Options.TimeoutMs
+		job.RateLimit = opts.RateLimit
+	}
+	if opts.Unique != nil {
+		if err := opts.Unique.normalize(); err != nil {
+			errs.add("options", err)
+		} else if key, err := opts.Unique.fingerprint(job); err == nil {
+			job.Unique = opts.Unique
+			job.UniqueKey = key
 		}
-		if req.Options.Tags != nil {
-			job.Tags = req.Options.Tags
+	}
+
+	var runAt time.Time
+	if opts.ScheduledAt != "" {
+		t, err := time.Parse(time.RFC3339, opts.ScheduledAt)
+		if err != nil {
+			errs.add("options.scheduled_at", fmt.Errorf("must be an RFC 3339 timestamp"))
 		}
-		if req.Options.ScheduledAt != "" {
+		job.ScheduledAt = opts.ScheduledAt
+		if t.After(time.Now()) {
 			job.State = StateScheduled
-			job.ScheduledAt = req.Options.ScheduledAt
+			runAt = t
 		}
 	}
+	if err := job.setExpiry(opts.ExpiresAt, time.Now()); err != nil {
+		errs.add("options.expires_at", err)
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, time.Time{}, err
+	}
+	return job, runAt, nil
+}
+
+// insertJob stores a prepared job, applying its unique policy. It returns
+// the stored job, or the existing one with created=false when the policy
+// ignored the new job. A job whose ID is taken is rejected. With withRoom
+// set, an available job must also fit within its queue's max_size; room is
+// made only once the job is certain to join the queue.
+// Must be called with m.mu held.
+func (m *MemoryBackend) insertJob(job *MemoryJob, runAt time.Time, withRoom bool) (*MemoryJob, bool, []transition, error) {
+	if _, ok := m.jobs[job.ID]; ok {
+		return nil, false, nil, duplicateID(job.ID)
+	}
+
+	var replaced *MemoryJob
+	if job.Unique != nil {
+		if dup := m.findDuplicate(job.UniqueKey, job.Unique, time.Now()); dup != nil {
+			switch job.Unique.OnConflict {
+			case OnConflictReject:
+				return nil, false, nil, &RequestError{
+					Status:  http.StatusConflict,
+					Code:    "duplicate",
+					Message: "A job with the same unique key already exists.",
+					Details: map[string]any{"existing_job_id": dup.ID},
+				}
+			case OnConflictIgnore:
+				return dup, false, nil, nil
+			case OnConflictReplaceExceptSchedule:
+				job.ScheduledAt = dup.ScheduledAt
+				if dup.State == StateScheduled {
+					job.State = StateScheduled
+					runAt = m.delayed[dup.ID]
+				}
+			}
+			if isValidTransition(dup.State, StateCancelled) {
+				replaced = dup
+			}
+		}
+	}
+	if withRoom && job.State == StateAvailable {
+		leaving := 0
+		if replaced != nil && replaced.State == StateAvailable && replaced.Queue == job.Queue {
+			leaving = 1
+		}
+		if err := m.checkRoom(job.Queue, leaving); err != nil {
+			return nil, false, nil, err
+		}
+	}
+
+	var changes []transition
+	if replaced != nil {
+		changes = append(changes, m.cancelJob(replaced))
+	}
+	if job.Unique != nil {
+		m.unique[job.UniqueKey] = uniqueEntry{jobID: job.ID, createdAt: time.Now()}
+	}
 
-	m.mu.Lock()
 	m.jobs[job.ID] = job
-	if job.State == StateAvailable {
+	if t, ok := job.expiry(); ok {
+		m.expiring[job.ID] = t
+	}
+	inserted := transition{job: job, fromState: "", toState: job.State}
+	switch job.State {
+	case StateAvailable:
+		// Rate limits apply from enqueue: a job whose limit is already
+		// reached is rescheduled or dropped at once.
+		now := time.Now()
+		hit := m.checkRateLimits(job, now)
+		inserted.rateLimit = hit
+		changes = append(changes, inserted)
+		if hit != nil {
+			if change := m.applyRateLimit(hit, now); change != nil {
+				return job, true, append(changes, *change), nil
+			}
+		}
+		if withRoom {
+			changes = append(changes, m.makeRoom(job.Queue)...)
+		}
 		m.addToQueue(job)
+		return job, true, changes, nil
+	case StateScheduled:
+		m.delayed[job.ID] = runAt
 	}
-	m.mu.Unlock()
 
-	if m.onStateChange != nil {
-		m.onStateChange(job, "", job.State)
-	}
+	changes = append(changes, inserted)
+	return job, true, changes, nil
+}
 
-	w.Header().Set("Location", "/ojs/v1/jobs/"+job.ID)
-	writeJSON(w, http.StatusCreated, map[string]any{"job": job})
+// duplicateID returns the error for enqueueing a job under an ID that is
+// taken.
+func duplicateID(id string) *RequestError {
+	return &RequestError{
+		Status:  http.StatusConflict,
+		Code:    "duplicate",
+		Message: "A job with ID " + id + " already exists.",
+		Details: map[string]any{"existing_job_id": id},
+	}
 }
 
-func (m *MemoryBackend) handleGetJob(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
+func (m *MemoryBackend) handleCreateJob(w http.ResponseWriter, r *http.Request) {
+	var req EnqueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeRequestError(w, decodeError(err))
+		return
+	}
 
-	m.mu.RLock()
-	job, ok := m.jobs[id]
-	m.mu.RUnlock()
+	job, created, err := m.EnqueueContext(r.Context(), &req)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
 
-	if !ok {
-		writeError(w, http.StatusNotFound, "not_found", "Job not found: "+id)
+	if !created {
+		writeJSON(w, http.StatusOK, map[string]any{"job": job})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"job": job})
+	w.Header().Set("Location", "/ojs/v1/jobs/"+job.ID)
+	writeJSON(w, http.StatusCreated, map[string]any{"job": job})
 }
 
-func (m *MemoryBackend) handleCancelJob(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
+func (m *MemoryBackend) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := JobFilter{State: query.Get("state"), Type: query.Get("type"), Queue: query.Get("queue")}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		filter.Limit, _ = strconv.Atoi(limitStr)
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		filter.Offset, _ = strconv.Atoi(offsetStr)
+	}
 
-	m.mu.Lock()
-	job, ok := m.jobs[id]
-	if !ok {
-		m.mu.Unlock()
-		writeError(w, http.StatusNotFound, "not_found", "Job not found: "+id)
-		return
+	jobs, total, _ := m.Jobs(r.Context(), filter)
+	if jobs == nil {
+		jobs = []*MemoryJob{}
 	}
+	writeJSON(w, http.StatusOK, map[string]any{"jobs": jobs, "total": total})
+}
 
-	fromState := job.State
-	if !isValidTransition(fromState, StateCancelled) {
-		m.mu.Unlock()
-		writeError(w, http.StatusConflict, "invalid_request",
-			fmt.Sprintf("Cannot cancel job in state %q.", fromState))
+func (m *MemoryBackend) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := m.Job(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
-	job.State = StateCancelled
-	job.CancelledAt = nowFormatted()
-	m.removeFromQueue(job)
-	m.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"job": job})
+}
 
-	if m.onStateChange != nil {
-		m.onStateChange(job, fromState, StateCancelled)
+func (m *MemoryBackend) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	job, err := m.CancelJob(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeRequestError(w, err)
+		return
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{"job": job})
 }
 
 func (m *MemoryBackend) handleFetch(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Queues   []string `json:"queues"`
-		Count    int      `json:"count,omitempty"`
-		WorkerID string   `json:"worker_id,omitempty"`
-	}
-
+	var req FetchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
 		return
 	}
 
-	if len(req.Queues) == 0 {
-		req.Queues = []string{"default"}
-	}
-	if req.Count <= 0 {
-		req.Count = 1
+	fetched, err := m.Fetch(r.Context(), req)
+	if err != nil {
+		writeRequestError(w, err)
+		return
 	}
-
-	m.mu.Lock()
-	var fetched []*MemoryJob
-	for _, q := range req.Queues {
-		if len(fetched) >= req.Count {
-			break
-		}
-		remaining := req.Count - len(fetched)
-		jobs := m.queues[q]
-		take := remaining
-		if take > len(jobs) {
-			take = len(jobs)
-		}
-		for i := 0; i < take; i++ {
-			job := jobs[i]
-			fromState := job.State
-			job.State = StateActive
-			job.StartedAt = nowFormatted()
-			job.Attempt++
-			fetched = append(fetched, job)
-			if m.onStateChange != nil {
-				defer func(j *MemoryJob, fs string) {
-					m.onStateChange(j, fs, StateActive)
-				}(job, fromState)
-			}
-		}
-		m.queues[q] = jobs[take:]
+	if fetched == nil {
+		fetched = []*MemoryJob{}
 	}
-	m.mu.Unlock()
 
 	writeJSON(w, http.StatusOK, map[string]any{"jobs": fetched})
 }
 
 func (m *MemoryBackend) handleAck(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		JobID  string          `json:"job_id"`
-		Result json.RawMessage `json:"result,omitempty"`
-	}
-
+	var req AckRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
 		return
 	}
 
-	m.mu.Lock()
-	job, ok := m.jobs[req.JobID]
-	if !ok {
-		m.mu.Unlock()
-		writeError(w, http.StatusNotFound, "not_found", "Job not found: "+req.JobID)
+	job, err := m.Ack(r.Context(), req)
+	if err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
-	fromState := job.State
-	if !isValidTransition(fromState, StateCompleted) {
-		m.mu.Unlock()
-		writeError(w, http.StatusConflict, "invalid_request",
-			fmt.Sprintf("Cannot ack job in state %q.", fromState))
-		return
-	}
-
-	job.State = StateCompleted
-	job.CompletedAt = nowFormatted()
-	if req.Result != nil {
-		job.Result = req.Result
-	}
-	m.mu.Unlock()
-
-	if m.onStateChange != nil {
-		m.onStateChange(job, fromState, StateCompleted)
-	}
-
 	writeJSON(w, http.StatusOK, map[string]any{"job": job})
 }
 
 func (m *MemoryBackend) handleNack(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		JobID   string          `json:"job_id"`
-		Error   json.RawMessage `json:"error,omitempty"`
-		Requeue bool            `json:"requeue,omitempty"`
-	}
-
+	var req NackRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
 		return
 	}
 
-	m.mu.Lock()
-	job, ok := m.jobs[req.JobID]
-	if !ok {
-		m.mu.Unlock()
-		writeError(w, http.StatusNotFound, "not_found", "Job not found: "+req.JobID)
-		return
-	}
-
-	fromState := job.State
-	targetState := StateRetryable
-	if job.Attempt >= job.MaxAttempts {
-		targetState = StateDiscarded
-	}
-
-	if !isValidTransition(fromState, targetState) {
-		m.mu.Unlock()
-		writeError(w, http.StatusConflict, "invalid_request",
-			fmt.Sprintf("Cannot nack job in state %q.", fromState))
+	job, err := m.Nack(r.Context(), req)
+	if err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
-	job.State = targetState
-	if req.Error != nil {
-		job.Error = req.Error
-	}
-
-	// If retryable, re-add to available after a brief moment
-	if targetState == StateRetryable {
-		job.State = StateAvailable
-		m.addToQueue(job)
-	}
-	m.mu.Unlock()
-
-	if m.onStateChange != nil {
-		m.onStateChange(job, fromState, targetState)
-	}
-
 	writeJSON(w, http.StatusOK, map[string]any{"job": job})
 }
 
 func (m *MemoryBackend) handleListQueues(w http.ResponseWriter, r *http.Request) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	type queueInfo struct {
-		Name      string `json:"name"`
-		Available int    `json:"available"`
-	}
-
-	var queues []queueInfo
-	seen := make(map[string]bool)
-
-	// Count available jobs per queue
-	for q, jobs := range m.queues {
-		queues = append(queues, queueInfo{Name: q, Available: len(jobs)})
-		seen[q] = true
-	}
-
-	// Include queues with no available jobs but existing jobs
-	for _, j := range m.jobs {
-		if !seen[j.Queue] {
-			queues = append(queues, queueInfo{Name: j.Queue, Available: 0})
-			seen[j.Queue] = true
-		}
+	queues, _ := m.Queues(r.Context())
+	if queues == nil {
+		queues = []QueueInfo{}
 	}
-
 	writeJSON(w, http.StatusOK, map[string]any{"queues": queues})
 }
 
-// addToQueue inserts a job into its queue sorted by priority (desc).
+// cancelJob moves a job to cancelled and releases everything it holds.
+// The caller must have checked the transition is valid.
+// Must be called with m.mu held.
+func (m *MemoryBackend) cancelJob(job *MemoryJob) transition {
+	fromState := job.State
+	job.State = StateCancelled
+	job.CancelledAt = nowFormatted()
+	m.removeFromQueue(job)
+	m.endLease(job)
+	m.markFinished(job)
+	delete(m.delayed, job.ID)
+	return transition{job: job, fromState: fromState, toState: StateCancelled}
+}
+
+// addToQueue inserts a job into its queue, behind jobs of higher or equal
+// priority.
 // Must be called with m.mu held.
 func (m *MemoryBackend) addToQueue(job *MemoryJob) {
-	q := m.queues[job.Queue]
-	q = append(q, job)
-	sort.Slice(q, func(i, j int) bool {
-		return q[i].Priority > q[j].Priority
-	})
-	m.queues[job.Queue] = q
+	q, ok := m.queues[job.Queue]
+	if !ok {
+		q = newJobQueue()
+		m.queues[job.Queue] = q
+	}
+	q.add(job)
 }
 
 // removeFromQueue removes a job from its queue.
 // Must be called with m.mu held.
 func (m *MemoryBackend) removeFromQueue(job *MemoryJob) {
-	q := m.queues[job.Queue]
-	for i, j := range q {
-		if j.ID == job.ID {
-			m.queues[job.Queue] = append(q[:i], q[i+1:]...)
-			return
-		}
+	if q, ok := m.queues[job.Queue]; ok {
+		q.remove(job.ID)
 	}
 }
 
@@ -507,7 +784,10 @@ func (m *MemoryBackend) GetJob(id string) (*MemoryJob, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	j, ok := m.jobs[id]
-	return j, ok
+	if !ok {
+		return nil, false
+	}
+	return j.clone(), true
 }
 
 // ListJobs returns all jobs (for use by API handlers).
@@ -517,7 +797,7 @@ func (m *MemoryBackend) ListJobs() []*MemoryJob {
 
 	jobs := make([]*MemoryJob, 0, len(m.jobs))
 	for _, j := range m.jobs {
-		jobs = append(jobs, j)
+		jobs = append(jobs, j.clone())
 	}
 	return jobs
 }
@@ -0,0 +1,28 @@
+package backends
+
+import "encoding/json"
+
+// ArgsValidator checks the args of a job of the given type against the
+// schema registered for it. Schema is the job's schema URI, if it names
+// one. Paths of the returned errors are relative to args, "" for args
+// itself.
+type ArgsValidator func(jobType, schema string, args json.RawMessage) []FieldError
+
+// SetArgsValidator makes enqueue reject jobs whose args do not match the
+// schema registered for their type.
+func (m *MemoryBackend) SetArgsValidator(fn ArgsValidator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.validateArgs = fn
+}
+
+// checkArgs records an error for each way args violate the job type's
+// schema.
+func checkArgs(errs *fieldErrors, req *EnqueueRequest, validate ArgsValidator) {
+	if validate == nil {
+		return
+	}
+	for _, fe := range validate(req.Type, req.Schema, req.Args) {
+		errs.add("args", &fe)
+	}
+}
@@ -0,0 +1,254 @@
+package backends
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// MaxBatchSize is the largest number of jobs accepted by a single batch
+// enqueue.
+const MaxBatchSize = 1000
+
+// Batch item statuses.
+const (
+	BatchCreated   = "created"
+	BatchDuplicate = "duplicate"
+	BatchFailed    = "failed"
+)
+
+// BatchRequest is the body of POST /ojs/v1/jobs/batch. When Atomic is set,
+// a single invalid job causes the whole batch to be rejected; otherwise
+// each job is enqueued independently.
+type BatchRequest struct {
+	Jobs   []*EnqueueRequest `json:"jobs"`
+	Atomic bool              `json:"atomic,omitempty"`
+}
+
+// BatchResult is the outcome of enqueueing one job of a batch. It mirrors
+// the UI's BulkOperationResult type.
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	JobID  string `json:"jobId,omitempty"`
+}
+
+// BatchResponse summarises a batch enqueue.
+type BatchResponse struct {
+	Total     int           `json:"total"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Items     []BatchResult `json:"items"`
+}
+
+// EnqueueBatch validates and stores a batch of jobs, reporting a result for
+// each. An atomic batch is stored whole or not at all: a job that fails
+// validation, does not fit in its queue or is rejected by its unique policy
+// rolls it back, as does a batch that alone would overflow a drop_oldest
+// queue. Otherwise jobs whose ID is taken or that a unique policy rejected
+// or ignored are reported as duplicates, and jobs a later one of the batch
+// dropped from a full queue as failed.
+func (m *MemoryBackend) EnqueueBatch(req *BatchRequest) (*BatchResponse, error) {
+	if len(req.Jobs) == 0 {
+		return nil, validationError("Field 'jobs' must contain at least one job.")
+	}
+	if len(req.Jobs) > MaxBatchSize {
+		return nil, &RequestError{
+			Status:  http.StatusUnprocessableEntity,
+			Code:    "batch_too_large",
+			Message: fmt.Sprintf("A batch may contain at most %d jobs.", MaxBatchSize),
+		}
+	}
+
+	items := make([]BatchResult, len(req.Jobs))
+	jobs := make([]*MemoryJob, len(req.Jobs))
+	runAts := make([]time.Time, len(req.Jobs))
+	invalid := false
+	for i, jr := range req.Jobs {
+		items[i].Index = i
+		if jr == nil {
+			items[i].Status = BatchFailed
+			items[i].Error = "Job must be an object."
+			invalid = true
+			continue
+		}
+		job, runAt, err := m.prepareJob(jr)
+		if err != nil {
+			items[i].Status = BatchFailed
+			items[i].Error = err.Error()
+			invalid = true
+			continue
+		}
+		jobs[i], runAts[i] = job, runAt
+	}
+
+	if req.Atomic && invalid {
+		for i := range items {
+			if items[i].Status != BatchFailed {
+				items[i].Status = BatchFailed
+				items[i].Error = "Batch rolled back because another job failed validation."
+			}
+		}
+		return summariseBatch(items), nil
+	}
+
+	m.mu.Lock()
+	if req.Atomic {
+		if i, err := m.checkBatchFits(jobs); err != nil {
+			m.mu.Unlock()
+			for j := range items {
+				items[j].Status = BatchFailed
+				items[j].Error = fmt.Sprintf("Batch rolled back because job %d could not be enqueued.", i)
+			}
+			items[i].Error = err.Error()
+			return summariseBatch(items), nil
+		}
+	}
+	var changes []transition
+	createdAt := make(map[string]int) // created job ID → item index
+	for i, job := range jobs {
+		if job == nil {
+			continue
+		}
+		stored, created, jobChanges, err := m.insertJob(job, runAts[i], true)
+		changes = append(changes, jobChanges...)
+		switch {
+		case err != nil:
+			items[i].Status = BatchFailed
+			items[i].Error = err.Error()
+			if reqErr, ok := err.(*RequestError); ok && reqErr.Code == "duplicate" {
+				items[i].Status = BatchDuplicate
+				if id, ok := reqErr.Details["existing_job_id"].(string); ok {
+					items[i].JobID = id
+				}
+			}
+		case !created:
+			items[i].Status = BatchDuplicate
+			items[i].JobID = stored.ID
+		default:
+			items[i].Status = BatchCreated
+			items[i].JobID = stored.ID
+			createdAt[stored.ID] = i
+		}
+	}
+	// With drop_oldest, a later job of the batch may have evicted an
+	// earlier one.
+	for _, c := range changes {
+		if i, ok := createdAt[c.job.ID]; ok && c.reason == "queue_overflow" {
+			items[i].Status = BatchFailed
+			items[i].Error = fmt.Sprintf("Dropped to make room in full queue %q for a later job of the batch.", c.job.Queue)
+		}
+	}
+	m.mu.Unlock()
+
+	m.notify(changes)
+	return summariseBatch(items), nil
+}
+
+// checkBatchFits reports the first of jobs that insertJob would
+// fail to store, with its error, given the jobs before it were stored.
+// Must be called with m.mu held.
+func (m *MemoryBackend) checkBatchFits(jobs []*MemoryJob) (int, error) {
+	queued := make(map[string]int)         // queue → jobs the batch adds to it
+	own := make(map[string]int)            // queue → batch jobs waiting in it
+	claimed := make(map[string]*MemoryJob) // unique key → batch job holding it
+	ids := make(map[string]bool)
+	now := time.Now()
+	for i, job := range jobs {
+		if _, ok := m.jobs[job.ID]; ok || ids[job.ID] {
+			return i, duplicateID(job.ID)
+		}
+		ids[job.ID] = true
+		if cfg, ok := m.queueConfigs[job.Queue]; ok && cfg.MaxSize > 0 {
+			depth := m.queueLen(job.Queue) + queued[job.Queue]
+			if cfg.OverflowPolicy == OverflowDropOldest {
+				// Older jobs make way, but the batch must not drop its own.
+				depth = own[job.Queue]
+			}
+			if depth >= cfg.MaxSize {
+				return i, queueFull(job.Queue, depth, cfg.MaxSize)
+			}
+		}
+
+		state := job.State
+		if job.Unique != nil {
+			dup := claimed[job.UniqueKey]
+			fromBatch := dup != nil
+			if dup == nil {
+				dup = m.findDuplicate(job.UniqueKey, job.Unique, now)
+			} else if !slices.Contains(job.Unique.States, dup.State) {
+				dup = nil
+			}
+			if dup != nil {
+				switch job.Unique.OnConflict {
+				case OnConflictReject:
+					return i, &RequestError{
+						Status:  http.StatusConflict,
+						Code:    "duplicate",
+						Message: "A job with the same unique key already exists.",
+						Details: map[string]any{"existing_job_id": dup.ID},
+					}
+				case OnConflictIgnore:
+					continue
+				case OnConflictReplaceExceptSchedule:
+					if dup.State == StateScheduled {
+						state = StateScheduled
+					}
+				}
+				// The duplicate is cancelled, leaving its queue.
+				if dup.State == StateAvailable {
+					queued[dup.Queue]--
+					if fromBatch {
+						own[dup.Queue]--
+					}
+				}
+			}
+			claimed[job.UniqueKey] = &MemoryJob{ID: job.ID, Queue: job.Queue, State: state}
+		}
+		if state == StateAvailable {
+			queued[job.Queue]++
+			own[job.Queue]++
+		}
+	}
+	return 0, nil
+}
+
+// queueLen returns the number of available jobs in a queue.
+// Must be called with m.mu held.
+func (m *MemoryBackend) queueLen(name string) int {
+	if q, ok := m.queues[name]; ok {
+		return q.Len()
+	}
+	return 0
+}
+
+func summariseBatch(items []BatchResult) *BatchResponse {
+	resp := &BatchResponse{Total: len(items), Items: items}
+	for _, item := range items {
+		switch item.Status {
+		case BatchCreated:
+			resp.Succeeded++
+		case BatchFailed:
+			resp.Failed++
+		}
+	}
+	return resp
+}
+
+func (m *MemoryBackend) handleBatchCreate(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeRequestError(w, decodeError(err))
+		return
+	}
+
+	resp, err := m.EnqueueBatch(&req)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,248 @@
+package backends
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// AckRequest is the body of POST /workers/ack.
+type AckRequest struct {
+	JobID    string          `json:"job_id"`
+	WorkerID string          `json:"worker_id,omitempty"`
+	Result   json.RawMessage `json:"result,omitempty"`
+}
+
+// NackRequest is the body of POST /workers/nack.
+type NackRequest struct {
+	JobID    string          `json:"job_id"`
+	WorkerID string          `json:"worker_id,omitempty"`
+	Error    json.RawMessage `json:"error,omitempty"`
+	Requeue  bool            `json:"requeue,omitempty"`
+}
+
+// EnqueueJob enqueues a job, waiting for room in a full queue as
+// EnqueueContext does.
+func (m *MemoryBackend) EnqueueJob(ctx context.Context, req *EnqueueRequest) (*MemoryJob, error) {
+	job, _, err := m.EnqueueContext(ctx, req)
+	return job, err
+}
+
+// Job returns a job by ID.
+func (m *MemoryBackend) Job(ctx context.Context, id string) (*MemoryJob, error) {
+	job, ok := m.GetJob(id)
+	if !ok {
+		return nil, jobNotFound(id)
+	}
+	return job, nil
+}
+
+// Jobs returns the jobs matching filter, newest first, and the total
+// number that matched.
+func (m *MemoryBackend) Jobs(ctx context.Context, filter JobFilter) ([]*MemoryJob, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matched []*MemoryJob
+	for _, j := range m.jobs {
+		if (filter.State != "" && j.State != filter.State) ||
+			(filter.Type != "" && j.Type != filter.Type) ||
+			(filter.Queue != "" && j.Queue != filter.Queue) {
+			continue
+		}
+		matched = append(matched, j.clone())
+	}
+	// IDs are UUIDv7, so they sort in creation order.
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+
+	total := len(matched)
+	if filter.Offset > len(matched) {
+		filter.Offset = len(matched)
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total, nil
+}
+
+// CancelJob cancels a job that has not finished.
+func (m *MemoryBackend) CancelJob(ctx context.Context, id string) (*MemoryJob, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, jobNotFound(id)
+	}
+
+	if !isValidTransition(job.State, StateCancelled) {
+		state := job.State
+		m.mu.Unlock()
+		return nil, invalidState("cancel", state)
+	}
+
+	change := m.cancelJob(job)
+	cancelled := job.clone()
+	m.mu.Unlock()
+
+	m.notify([]transition{change})
+	return cancelled, nil
+}
+
+// RetryJob makes a failed or cancelled job available again now. A
+// retryable job keeps its attempt count; a cancelled or discarded one,
+// including a dead letter, starts over like a dead letter replay.
+func (m *MemoryBackend) RetryJob(ctx context.Context, id string) (*MemoryJob, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, jobNotFound(id)
+	}
+
+	fromState := job.State
+	switch fromState {
+	case StateRetryable:
+		delete(m.delayed, job.ID)
+		job.NextAttemptAt = ""
+	case StateCancelled, StateDiscarded:
+		delete(m.dead, job.ID)
+		job.Attempt = 0
+		job.Error = nil
+		job.CancelledAt = ""
+		job.DiscardedAt = ""
+	default:
+		m.mu.Unlock()
+		return nil, invalidState("retry", fromState)
+	}
+	job.State = StateAvailable
+	job.EnqueuedAt = nowFormatted()
+	m.addToQueue(job)
+	retried := job.clone()
+	m.mu.Unlock()
+
+	m.notify([]transition{{job: job, fromState: fromState, toState: StateAvailable}})
+	return retried, nil
+}
+
+// Ack completes an active job, keeping its result.
+func (m *MemoryBackend) Ack(ctx context.Context, req AckRequest) (*MemoryJob, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[req.JobID]
+	if !ok {
+		m.mu.Unlock()
+		return nil, jobNotFound(req.JobID)
+	}
+
+	fromState := job.State
+	if !isValidTransition(fromState, StateCompleted) {
+		m.mu.Unlock()
+		return nil, invalidState("ack", fromState)
+	}
+	if err := checkLeaseHolder(job, req.WorkerID); err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	job.State = StateCompleted
+	job.CompletedAt = nowFormatted()
+	m.endLease(job)
+	m.markFinished(job)
+	if req.Result != nil {
+		job.Result = req.Result
+	}
+	completed := job.clone()
+	m.mu.Unlock()
+
+	m.notify([]transition{{job: job, fromState: fromState, toState: StateCompleted}})
+	return completed, nil
+}
+
+// Nack fails an active job, which is retried or discarded as its retry
+// policy says.
+func (m *MemoryBackend) Nack(ctx context.Context, req NackRequest) (*MemoryJob, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[req.JobID]
+	if !ok {
+		m.mu.Unlock()
+		return nil, jobNotFound(req.JobID)
+	}
+
+	if !isValidTransition(job.State, StateRetryable) {
+		state := job.State
+		m.mu.Unlock()
+		return nil, invalidState("nack", state)
+	}
+	if err := checkLeaseHolder(job, req.WorkerID); err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	changes := m.failJob(job, req.Error, req.Requeue, time.Now())
+	failed := job.clone()
+	m.mu.Unlock()
+
+	m.notify(changes)
+	return failed, nil
+}
+
+// Queues returns every queue that holds jobs or is paused, by name.
+func (m *MemoryBackend) Queues(ctx context.Context) ([]QueueInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var queues []QueueInfo
+	seen := make(map[string]bool)
+
+	// Count available jobs per queue
+	for name, q := range m.queues {
+		queues = append(queues, QueueInfo{Name: name, Available: q.Len(), Paused: m.isQueuePaused(name)})
+		seen[name] = true
+	}
+
+	// Include queues with no available jobs but existing jobs
+	for _, j := range m.jobs {
+		if !seen[j.Queue] {
+			queues = append(queues, QueueInfo{Name: j.Queue, Available: 0, Paused: m.isQueuePaused(j.Queue)})
+			seen[j.Queue] = true
+		}
+	}
+
+	// Include paused queues that have never held a job
+	for q := range m.pausedQueues {
+		if !seen[q] {
+			queues = append(queues, QueueInfo{Name: q, Available: 0, Paused: true})
+			seen[q] = true
+		}
+	}
+
+	sort.Slice(queues, func(i, j int) bool { return queues[i].Name < queues[j].Name })
+	return queues, nil
+}
+
+// SetQueuePaused pauses or resumes a queue.
+func (m *MemoryBackend) SetQueuePaused(ctx context.Context, name string, paused bool) error {
+	if paused {
+		m.PauseQueue(name)
+	} else {
+		m.ResumeQueue(name)
+	}
+	return nil
+}
+
+// jobNotFound reports a job ID the backend does not hold.
+func jobNotFound(id string) *RequestError {
+	return &RequestError{Status: http.StatusNotFound, Code: "not_found", Message: "Job not found: " + id}
+}
+
+// invalidState reports a job operation its state does not allow.
+func invalidState(op, state string) *RequestError {
+	return &RequestError{
+		Status:  http.StatusConflict,
+		Code:    "invalid_request",
+		Message: fmt.Sprintf("Cannot %s job in state %q.", op, state),
+	}
+}
@@ -0,0 +1,268 @@
+package backends
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// defaultDeadLetterQueue receives dead-lettered jobs from queues that do
+// not configure their own.
+const defaultDeadLetterQueue = "dead"
+
+// DeadLetter is a job that exhausted its retries and was kept for
+// inspection and replay instead of being dropped.
+type DeadLetter struct {
+	Job             *MemoryJob `json:"job"`
+	DeadLetterQueue string     `json:"dead_letter_queue"`
+	DeadAt          string     `json:"dead_at"`
+}
+
+// DeadLetterFilter specifies filters for listing dead letters.
+type DeadLetterFilter struct {
+	DeadLetterQueue string
+	Limit           int
+	Offset          int
+}
+
+// deadLetterFor returns the dead letter queue a discarded job belongs in:
+// its queue's, or the default one. It returns "" if the job's policy says
+// to simply discard it.
+// Must be called with m.mu held.
+func (m *MemoryBackend) deadLetterFor(job *MemoryJob) string {
+	if job.Retry == nil || job.Retry.OnExhaustion != OnExhaustionDeadLetter {
+		return ""
+	}
+	if cfg, ok := m.queueConfigs[job.Queue]; ok && cfg.DeadLetterQueue != "" {
+		return cfg.DeadLetterQueue
+	}
+	return defaultDeadLetterQueue
+}
+
+// clone returns a copy of the dead letter and its job that is safe to use
+// without m.mu.
+func (dl *DeadLetter) clone() *DeadLetter {
+	c := *dl
+	c.Job = dl.Job.clone()
+	return &c
+}
+
+// ListDeadLetters returns dead letters, oldest first, and the total
+// number that matched the filter.
+func (m *MemoryBackend) ListDeadLetters(filter DeadLetterFilter) ([]*DeadLetter, int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matched []*DeadLetter
+	for _, dl := range m.dead {
+		if filter.DeadLetterQueue != "" && dl.DeadLetterQueue != filter.DeadLetterQueue {
+			continue
+		}
+		matched = append(matched, dl)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].DeadAt < matched[j].DeadAt })
+
+	total := len(matched)
+	if filter.Offset > len(matched) {
+		filter.Offset = len(matched)
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	for i, dl := range matched {
+		matched[i] = dl.clone()
+	}
+	return matched, total
+}
+
+// GetDeadLetter returns the dead letter for a job ID.
+func (m *MemoryBackend) GetDeadLetter(id string) (*DeadLetter, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	dl, ok := m.dead[id]
+	if !ok {
+		return nil, false
+	}
+	return dl.clone(), true
+}
+
+// ReplayDeadLetters moves dead-lettered jobs back to available with a fresh
+// attempt count. With no IDs, every job in filterQueue is replayed (or every
+// dead letter if filterQueue is empty). It returns the replayed jobs.
+func (m *MemoryBackend) ReplayDeadLetters(ids []string, filterQueue string) []*MemoryJob {
+	m.mu.Lock()
+	var replayed []*MemoryJob
+	var changes []transition
+	for _, dl := range m.selectDeadLetters(ids, filterQueue) {
+		job := dl.Job
+		delete(m.dead, job.ID)
+
+		// Dead letters are discarded, a terminal state; replay is the one
+		// sanctioned way out of it.
+		fromState := job.State
+		job.State = StateAvailable
+		job.Attempt = 0
+		job.Error = nil
+		job.DiscardedAt = ""
+		job.EnqueuedAt = nowFormatted()
+		m.addToQueue(job)
+		replayed = append(replayed, job.clone())
+		changes = append(changes, transition{job: job, fromState: fromState, toState: StateAvailable})
+	}
+	m.mu.Unlock()
+
+	m.notify(changes)
+	return replayed
+}
+
+// PurgeDeadLetters permanently removes dead letters, selected the same way
+// as ReplayDeadLetters. The jobs stay discarded. It returns how many were
+// removed.
+func (m *MemoryBackend) PurgeDeadLetters(ids []string, filterQueue string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	selected := m.selectDeadLetters(ids, filterQueue)
+	for _, dl := range selected {
+		delete(m.dead, dl.Job.ID)
+	}
+	return len(selected)
+}
+
+// selectDeadLetters resolves a replay or purge selection.
+// Must be called with m.mu held.
+func (m *MemoryBackend) selectDeadLetters(ids []string, filterQueue string) []*DeadLetter {
+	var selected []*DeadLetter
+	if len(ids) > 0 {
+		for _, id := range ids {
+			if dl, ok := m.dead[id]; ok {
+				selected = append(selected, dl)
+			}
+		}
+		return selected
+	}
+	for _, dl := range m.dead {
+		if filterQueue == "" || dl.DeadLetterQueue == filterQueue {
+			selected = append(selected, dl)
+		}
+	}
+	return selected
+}
+
+func (m *MemoryBackend) handleListDead(w http.ResponseWriter, r *http.Request) {
+	filter := DeadLetterFilter{DeadLetterQueue: r.URL.Query().Get("queue")}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		filter.Limit, _ = strconv.Atoi(limitStr)
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		filter.Offset, _ = strconv.Atoi(offsetStr)
+	}
+
+	dead, total := m.ListDeadLetters(filter)
+	if dead == nil {
+		dead = []*DeadLetter{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"dead": dead, "total": total})
+}
+
+func (m *MemoryBackend) handleGetDead(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	dl, ok := m.GetDeadLetter(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Dead letter not found: "+id)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"dead": dl})
+}
+
+func (m *MemoryBackend) handleReplayDead(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if _, ok := m.GetDeadLetter(id); !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Dead letter not found: "+id)
+		return
+	}
+
+	replayed := m.ReplayDeadLetters([]string{id}, "")
+	if len(replayed) == 0 {
+		writeError(w, http.StatusNotFound, "not_found", "Dead letter not found: "+id)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"job": replayed[0]})
+}
+
+// DeadSelection is the body of the bulk replay and purge endpoints. An
+// empty selection replays every dead letter; a purge of every dead letter
+// must set All.
+type DeadSelection struct {
+	IDs   []string `json:"ids,omitempty"`
+	Queue string   `json:"queue,omitempty"`
+	All   bool     `json:"all,omitempty"`
+}
+
+// Empty reports whether the selection names no dead letters, queue or All.
+func (s DeadSelection) Empty() bool {
+	return len(s.IDs) == 0 && s.Queue == "" && !s.All
+}
+
+// DecodeDeadSelection reads a DeadSelection from the request body, or from
+// the queue and all query parameters when the body is empty.
+func DecodeDeadSelection(r *http.Request) (DeadSelection, error) {
+	var sel DeadSelection
+	if r.ContentLength != 0 {
+		err := json.NewDecoder(r.Body).Decode(&sel)
+		if !errors.Is(err, io.EOF) {
+			return sel, err
+		}
+	}
+	query := r.URL.Query()
+	sel.Queue = query.Get("queue")
+	sel.All = query.Get("all") == "true"
+	return sel, nil
+}
+
+// ErrPurgeNeedsSelection is reported for a purge whose selection is empty.
+var ErrPurgeNeedsSelection = errors.New(`Select dead letters by "ids" or "queue", or set "all" to purge every one.`)
+
+func (m *MemoryBackend) handleReplayDeadBulk(w http.ResponseWriter, r *http.Request) {
+	sel, err := DecodeDeadSelection(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
+		return
+	}
+
+	replayed := m.ReplayDeadLetters(sel.IDs, sel.Queue)
+	if replayed == nil {
+		replayed = []*MemoryJob{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"jobs": replayed, "replayed": len(replayed)})
+}
+
+func (m *MemoryBackend) handlePurgeDead(w http.ResponseWriter, r *http.Request) {
+	if id := chi.URLParam(r, "id"); id != "" {
+		if m.PurgeDeadLetters([]string{id}, "") == 0 {
+			writeError(w, http.StatusNotFound, "not_found", "Dead letter not found: "+id)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"purged": 1})
+		return
+	}
+
+	sel, err := DecodeDeadSelection(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
+		return
+	}
+	if sel.Empty() {
+		writeError(w, http.StatusBadRequest, "invalid_request", ErrPurgeNeedsSelection.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"purged": m.PurgeDeadLetters(sel.IDs, sel.Queue)})
+}
@@ -0,0 +1,98 @@
+package backends
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openjobspec/ojs-playground/server/internal/sse"
+)
+
+// ReasonExpired is the error type and state change reason of a job that
+// was not fetched before it expired.
+const ReasonExpired = "expired"
+
+// setExpiry sets when the job expires from an explicit expires_at and the
+// timeout's enqueue_ttl, whichever comes first. The error describes a bad
+// expires_at.
+func (j *MemoryJob) setExpiry(expiresAt string, now time.Time) error {
+	var expiry time.Time
+	if expiresAt != "" {
+		t, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			return fmt.Errorf("must be an RFC 3339 timestamp")
+		}
+		expiry = t
+	}
+	if j.Timeout != nil && j.Timeout.EnqueueTTL > 0 {
+		t := now.Add(time.Duration(j.Timeout.EnqueueTTL) * time.Second)
+		if expiry.IsZero() || t.Before(expiry) {
+			expiry = t
+		}
+	}
+	if !expiry.IsZero() {
+		j.ExpiresAt = formatTime(expiry)
+	}
+	return nil
+}
+
+// expiry returns when the job expires, if it has not started by then.
+func (j *MemoryJob) expiry() (time.Time, bool) {
+	if j.ExpiresAt == "" || j.Attempt > 0 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, j.ExpiresAt)
+	return t, err == nil
+}
+
+// isExpired reports whether a waiting job that never started has passed
+// its expiry.
+// Must be called with m.mu held.
+func (m *MemoryBackend) isExpired(job *MemoryJob, now time.Time) bool {
+	if job.State != StateAvailable && job.State != StateScheduled {
+		return false
+	}
+	t, ok := job.expiry()
+	return ok && !now.Before(t)
+}
+
+// expireJob discards a job that expired before it was fetched.
+// Must be called with m.mu held.
+func (m *MemoryBackend) expireJob(job *MemoryJob) transition {
+	return m.discardUnstarted(job, ReasonExpired,
+		fmt.Sprintf("Job was not started before it expired at %s.", job.ExpiresAt))
+}
+
+// expireUnstarted discards waiting jobs that have passed their expiry.
+// Must be called with m.mu held.
+func (m *MemoryBackend) expireUnstarted(now time.Time) []transition {
+	var changes []transition
+	for id, t := range m.expiring {
+		job, ok := m.jobs[id]
+		if !ok || (job.State != StateAvailable && job.State != StateScheduled) || job.Attempt > 0 {
+			// Started, cancelled or forgotten; it can no longer expire.
+			delete(m.expiring, id)
+			continue
+		}
+		if now.Before(t) {
+			continue
+		}
+		changes = append(changes, m.expireJob(job))
+	}
+	return changes
+}
+
+// broadcastExpired announces the jobs among changes that expired.
+func (m *MemoryBackend) broadcastExpired(changes []transition) {
+	for _, c := range changes {
+		if c.reason != ReasonExpired {
+			continue
+		}
+		m.broadcast(sse.EventJobExpired, c.job.Queue, c.job.ID, map[string]any{
+			"job_id":     c.job.ID,
+			"type":       c.job.Type,
+			"queue":      c.job.Queue,
+			"from_state": c.fromState,
+			"expires_at": c.job.ExpiresAt,
+		})
+	}
+}
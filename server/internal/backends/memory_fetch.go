@@ -0,0 +1,180 @@
+package backends
+
+import (
+	"context"
+	"time"
+)
+
+// maxFetchWait bounds how long a fetch with wait_ms waits for a job.
+const maxFetchWait = 30 * time.Second
+
+// FetchRequest is the body of POST /workers/fetch. With WaitMs set, a
+// fetch that finds no job waits up to that long for one to arrive.
+type FetchRequest struct {
+	Queues   []string `json:"queues"`
+	Count    int      `json:"count,omitempty"`
+	WorkerID string   `json:"worker_id,omitempty"`
+	WaitMs   int      `json:"wait_ms,omitempty"`
+}
+
+// fetchWaiter is a fetch waiting for jobs. Jobs claimed for it are sent
+// on jobs, which is buffered so they can be handed over with m.mu held.
+type fetchWaiter struct {
+	queues   []string
+	count    int
+	workerID string
+	jobs     chan []*MemoryJob
+}
+
+// Fetch claims up to req.Count available jobs from req.Queues, in order.
+// If there are none and req.WaitMs is set, it waits for jobs until the
+// wait is over or ctx is done, whichever is first, and returns nil if
+// none arrived. Concurrent waiting fetches are served in the order they
+// started waiting.
+func (m *MemoryBackend) Fetch(ctx context.Context, req FetchRequest) ([]*MemoryJob, error) {
+	if req.WaitMs < 0 {
+		return nil, validationError("Field 'wait_ms' must not be negative.")
+	}
+	if len(req.Queues) == 0 {
+		req.Queues = []string{"default"}
+	}
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	wait := time.Duration(req.WaitMs) * time.Millisecond
+	if wait > maxFetchWait {
+		wait = maxFetchWait
+	}
+
+	m.mu.Lock()
+	fetched, changes, hits := m.claimJobs(req.Queues, req.Count, req.WorkerID, time.Now())
+	var w *fetchWaiter
+	if len(fetched) == 0 && wait > 0 {
+		w = &fetchWaiter{queues: req.Queues, count: req.Count, workerID: req.WorkerID, jobs: make(chan []*MemoryJob, 1)}
+		m.waiters = append(m.waiters, w)
+	}
+	m.mu.Unlock()
+
+	m.notify(changes)
+	m.broadcastRateLimitHits(hits)
+	if w == nil {
+		return fetched, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case jobs := <-w.jobs:
+		return jobs, nil
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+
+	m.mu.Lock()
+	waiting := m.removeWaiter(w)
+	m.mu.Unlock()
+	if !waiting {
+		// Jobs were handed over as the wait ended.
+		return <-w.jobs, nil
+	}
+	return nil, nil
+}
+
+// claimJobs moves up to count available jobs from queues to active, leased
+// to workerID, skipping paused queues and jobs held back by rate limits.
+// Must be called with m.mu held.
+func (m *MemoryBackend) claimJobs(queues []string, count int, workerID string, now time.Time) ([]*MemoryJob, []transition, []*rateLimitHit) {
+	var fetched []*MemoryJob
+	var changes []transition
+	var hits []*rateLimitHit
+	for _, q := range queues {
+		if len(fetched) >= count {
+			break
+		}
+		if m.isQueuePaused(q) {
+			continue
+		}
+		queue, ok := m.queues[q]
+		if !ok {
+			continue
+		}
+		var waiting []queuedJob
+		for len(fetched) < count && len(waiting) < maxRateLimitSkips {
+			item, ok := queue.take()
+			if !ok {
+				break
+			}
+			job := item.job
+			if m.isExpired(job, now) {
+				changes = append(changes, m.expireJob(job))
+				continue
+			}
+			if hit := m.checkRateLimits(job, now); hit != nil {
+				hits = append(hits, hit)
+				if change := m.applyRateLimit(hit, now); change != nil {
+					changes = append(changes, *change)
+					continue
+				}
+				waiting = append(waiting, item)
+				if hit.queue {
+					break // the whole queue is held back
+				}
+				continue
+			}
+			m.acquireRateLimits(job, now)
+			fromState := job.State
+			job.State = StateActive
+			job.StartedAt = nowFormatted()
+			job.Attempt++
+			job.WorkerID = workerID
+			m.startLease(job, now)
+			fetched = append(fetched, job.clone())
+			changes = append(changes, transition{job: job, fromState: fromState, toState: StateActive})
+		}
+		for _, item := range waiting {
+			queue.putBack(item)
+		}
+	}
+	return fetched, changes, hits
+}
+
+// serveWaiters hands available jobs to waiting fetches, longest waiting
+// first.
+func (m *MemoryBackend) serveWaiters(now time.Time) {
+	m.mu.Lock()
+	if len(m.waiters) == 0 {
+		m.mu.Unlock()
+		return
+	}
+	var changes []transition
+	var hits []*rateLimitHit
+	waiters := m.waiters[:0]
+	for _, w := range m.waiters {
+		fetched, c, h := m.claimJobs(w.queues, w.count, w.workerID, now)
+		changes = append(changes, c...)
+		hits = append(hits, h...)
+		if len(fetched) > 0 {
+			w.jobs <- fetched
+			continue
+		}
+		waiters = append(waiters, w)
+	}
+	clear(m.waiters[len(waiters):])
+	m.waiters = waiters
+	m.mu.Unlock()
+
+	m.notify(changes)
+	m.broadcastRateLimitHits(hits)
+}
+
+// removeWaiter stops w waiting and reports whether it was still waiting.
+// Must be called with m.mu held.
+func (m *MemoryBackend) removeWaiter(w *fetchWaiter) bool {
+	for i, other := range m.waiters {
+		if other == w {
+			m.waiters = append(m.waiters[:i], m.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package backends
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// defaultVisibilityTimeout is how long an active job may go without a
+// heartbeat when its timeout policy does not set one.
+const defaultVisibilityTimeout = 30 * time.Minute
+
+// TimeoutPolicy is the OJS timeout policy. All values are in seconds.
+// On the wire it may also be a bare integer, meaning the execution timeout.
+type TimeoutPolicy struct {
+	Execution      int `json:"execution,omitempty"`
+	Heartbeat      int `json:"heartbeat,omitempty"`
+	HeartbeatGrace int `json:"heartbeat_grace,omitempty"`
+	EnqueueTTL     int `json:"enqueue_ttl,omitempty"`
+}
+
+// UnmarshalJSON accepts either an integer execution timeout or a policy object.
+func (t *TimeoutPolicy) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '{' {
+		var secs int
+		if err := json.Unmarshal(data, &secs); err != nil {
+			return fmt.Errorf("timeout must be an integer or an object")
+		}
+		*t = TimeoutPolicy{Execution: secs}
+		return nil
+	}
+	type plain TimeoutPolicy
+	return json.Unmarshal(data, (*plain)(t))
+}
+
+func (t *TimeoutPolicy) validate() error {
+	for name, v := range map[string]int{
+		"execution": t.Execution, "heartbeat": t.Heartbeat, "heartbeat_grace": t.HeartbeatGrace, "enqueue_ttl": t.EnqueueTTL,
+	} {
+		if v < 0 {
+			return &FieldError{Path: "timeout." + name, Message: "must not be negative"}
+		}
+	}
+	return nil
+}
+
+// HeartbeatRequest is the body of POST /workers/heartbeat.
+type HeartbeatRequest struct {
+	JobID    string `json:"job_id"`
+	WorkerID string `json:"worker_id,omitempty"`
+}
+
+// lease tracks the deadlines of an active job.
+type lease struct {
+	startedAt time.Time
+	lastBeat  time.Time
+}
+
+// executionTimeout returns the job's execution limit, or 0 for none.
+// An explicit timeout_ms takes precedence over timeout.execution.
+func (j *MemoryJob) executionTimeout() time.Duration {
+	if j.TimeoutMs != nil && *j.TimeoutMs > 0 {
+		return time.Duration(*j.TimeoutMs) * time.Millisecond
+	}
+	if j.Timeout != nil && j.Timeout.Execution > 0 {
+		return time.Duration(j.Timeout.Execution) * time.Second
+	}
+	return 0
+}
+
+// heartbeatTimeout returns how long the job may go between heartbeats,
+// including the grace period.
+func (j *MemoryJob) heartbeatTimeout() time.Duration {
+	d := defaultVisibilityTimeout
+	if j.Timeout != nil && j.Timeout.Heartbeat > 0 {
+		d = time.Duration(j.Timeout.Heartbeat) * time.Second
+	}
+	if j.Timeout != nil {
+		d += time.Duration(j.Timeout.HeartbeatGrace) * time.Second
+	}
+	return d
+}
+
+// deadline returns the earlier of the execution and heartbeat deadlines,
+// and which of the two it is.
+func (l *lease) deadline(job *MemoryJob) (time.Time, string) {
+	deadline := l.lastBeat.Add(job.heartbeatTimeout())
+	reason := "heartbeat"
+	if exec := job.executionTimeout(); exec > 0 {
+		if d := l.startedAt.Add(exec); d.Before(deadline) {
+			deadline, reason = d, "execution"
+		}
+	}
+	return deadline, reason
+}
+
+// startLease begins tracking an active job.
+// Must be called with m.mu held.
+func (m *MemoryBackend) startLease(job *MemoryJob, now time.Time) {
+	l := &lease{startedAt: now, lastBeat: now}
+	m.leases[job.ID] = l
+	d, _ := l.deadline(job)
+	job.LeaseExpiresAt = formatTime(d)
+}
+
+// checkLeaseHolder returns an error unless workerID holds the job. Jobs
+// fetched without a worker may be settled by any request; a job with a
+// holder must be settled by a request naming it.
+// Must be called with m.mu held.
+func checkLeaseHolder(job *MemoryJob, workerID string) error {
+	if job.WorkerID == "" || workerID == job.WorkerID {
+		return nil
+	}
+	return leaseMismatch(job.ID, job.WorkerID, workerID)
+}
+
+// leaseMismatch reports a request from a worker that does not hold the job,
+// or that names no worker.
+func leaseMismatch(jobID, holder, workerID string) *RequestError {
+	message := fmt.Sprintf("Job %s is leased to worker %q, not %q.", jobID, holder, workerID)
+	if workerID == "" {
+		message = fmt.Sprintf("Job %s is leased to worker %q; worker_id is required.", jobID, holder)
+	}
+	return &RequestError{
+		Status:  http.StatusConflict,
+		Code:    "lease_mismatch",
+		Message: message,
+	}
+}
+
+// WorkerJobs returns the active jobs held by a worker, oldest first.
+func (m *MemoryBackend) WorkerJobs(workerID string) []*MemoryJob {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var jobs []*MemoryJob
+	for id := range m.leases {
+		if job, ok := m.jobs[id]; ok && job.WorkerID == workerID {
+			jobs = append(jobs, job.clone())
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return m.leases[jobs[i].ID].startedAt.Before(m.leases[jobs[j].ID].startedAt)
+	})
+	return jobs
+}
+
+// endLease stops tracking a job that left the active state.
+// Must be called with m.mu held.
+func (m *MemoryBackend) endLease(job *MemoryJob) {
+	delete(m.leases, job.ID)
+	job.LeaseExpiresAt = ""
+	m.releaseRateLimits(job)
+}
+
+// reapExpired fails active jobs whose lease has run out.
+// Must be called with m.mu held.
+func (m *MemoryBackend) reapExpired(now time.Time) []transition {
+	var changes []transition
+	for id, l := range m.leases {
+		job, ok := m.jobs[id]
+		if !ok {
+			delete(m.leases, id)
+			continue
+		}
+		d, reason := l.deadline(job)
+		if now.Before(d) {
+			continue
+		}
+		jobErr, _ := json.Marshal(map[string]any{
+			"type":    "timeout",
+			"message": fmt.Sprintf("Job exceeded its %s timeout.", reason),
+		})
+		changes = append(changes, m.failJob(job, jobErr, false, now)...)
+	}
+	return changes
+}
+
+func (m *MemoryBackend) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var req HeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
+		return
+	}
+
+	m.mu.Lock()
+	job, ok := m.jobs[req.JobID]
+	if !ok {
+		m.mu.Unlock()
+		writeError(w, http.StatusNotFound, "not_found", "Job not found: "+req.JobID)
+		return
+	}
+
+	l, ok := m.leases[job.ID]
+	if !ok || job.State != StateActive {
+		m.mu.Unlock()
+		writeError(w, http.StatusConflict, "invalid_request",
+			fmt.Sprintf("Cannot heartbeat job in state %q.", job.State))
+		return
+	}
+	if err := checkLeaseHolder(job, req.WorkerID); err != nil {
+		m.mu.Unlock()
+		writeRequestError(w, err)
+		return
+	}
+
+	l.lastBeat = time.Now()
+	d, _ := l.deadline(job)
+	job.LeaseExpiresAt = formatTime(d)
+	beat := job.clone()
+	m.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{"job": beat})
+}
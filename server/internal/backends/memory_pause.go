@@ -0,0 +1,108 @@
+package backends
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openjobspec/ojs-playground/server/internal/chaos"
+	"github.com/openjobspec/ojs-playground/server/internal/sse"
+)
+
+// SetBroadcaster sets where the backend publishes events that are not job
+// state changes, such as queue pauses.
+func (m *MemoryBackend) SetBroadcaster(b *sse.Broadcaster) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.broadcaster = b
+}
+
+// SetChaosConfig makes fetch honour queues paused through the chaos panel.
+func (m *MemoryBackend) SetChaosConfig(c *chaos.Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.chaos = c
+}
+
+// broadcast publishes an event if a broadcaster is set.
+func (m *MemoryBackend) broadcast(eventType, queue, jobID string, data map[string]any) {
+	m.mu.RLock()
+	b := m.broadcaster
+	m.mu.RUnlock()
+	if b == nil {
+		return
+	}
+	b.Broadcast(sse.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		JobID:     jobID,
+		Queue:     queue,
+		Data:      data,
+	})
+}
+
+// PauseQueue stops fetch from handing out jobs from the queue.
+func (m *MemoryBackend) PauseQueue(name string) {
+	m.mu.Lock()
+	m.pausedQueues[name] = true
+	m.mu.Unlock()
+	m.broadcast(sse.EventQueuePaused, name, "", map[string]any{"queue": name, "backend": m.Name()})
+}
+
+// ResumeQueue lets fetch hand out jobs from the queue again.
+func (m *MemoryBackend) ResumeQueue(name string) {
+	m.mu.Lock()
+	delete(m.pausedQueues, name)
+	m.mu.Unlock()
+	m.broadcast(sse.EventQueueResumed, name, "", map[string]any{"queue": name, "backend": m.Name()})
+}
+
+// Pause stops fetch from handing out jobs from any queue.
+func (m *MemoryBackend) Pause() {
+	m.mu.Lock()
+	m.paused = true
+	m.mu.Unlock()
+	m.broadcast(sse.EventQueuePaused, "", "", map[string]any{"backend": m.Name()})
+}
+
+// Resume undoes Pause. Individually paused queues stay paused.
+func (m *MemoryBackend) Resume() {
+	m.mu.Lock()
+	m.paused = false
+	m.mu.Unlock()
+	m.broadcast(sse.EventQueueResumed, "", "", map[string]any{"backend": m.Name()})
+}
+
+// isQueuePaused reports whether fetch must skip the queue.
+// Must be called with m.mu held.
+func (m *MemoryBackend) isQueuePaused(name string) bool {
+	if m.paused || m.pausedQueues[name] {
+		return true
+	}
+	return m.chaos != nil && m.chaos.IsQueuePaused(name)
+}
+
+// pausedQueueNames returns the individually paused queues, sorted.
+// Must be called with m.mu held.
+func (m *MemoryBackend) pausedQueueNames() []string {
+	names := make([]string, 0, len(m.pausedQueues))
+	for q := range m.pausedQueues {
+		names = append(names, q)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m *MemoryBackend) handlePauseQueue(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	m.PauseQueue(name)
+	writeJSON(w, http.StatusOK, map[string]any{"queue": map[string]any{"name": name, "paused": true}})
+}
+
+func (m *MemoryBackend) handleResumeQueue(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	m.ResumeQueue(name)
+	writeJSON(w, http.StatusOK, map[string]any{"queue": map[string]any{"name": name, "paused": false}})
+}
@@ -0,0 +1,115 @@
+package backends
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openjobspec/ojs-playground/server/internal/sse"
+)
+
+// ProgressUpdate is an OJS progress report for an active job. Value is the
+// fraction of work done, from 0 to 1. Checkpoint is opaque state a worker
+// can use to resume the job on a later attempt.
+type ProgressUpdate struct {
+	Value      float64         `json:"value"`
+	Message    string          `json:"message,omitempty"`
+	Checkpoint json.RawMessage `json:"checkpoint,omitempty"`
+	UpdatedAt  string          `json:"updated_at,omitempty"`
+}
+
+// ProgressCallback is called when an active job reports progress.
+type ProgressCallback func(jobID string, progress ProgressUpdate)
+
+// SetProgressCallback sets the function called after each progress report.
+func (m *MemoryBackend) SetProgressCallback(fn ProgressCallback) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onProgress = fn
+}
+
+func (p *ProgressUpdate) validate() error {
+	if p.Value < 0 || p.Value > 1 {
+		return fmt.Errorf("progress value must be between 0 and 1")
+	}
+	if len(p.Checkpoint) > 0 {
+		var v any
+		if err := json.Unmarshal(p.Checkpoint, &v); err != nil {
+			return fmt.Errorf("progress checkpoint must be valid JSON")
+		}
+		if _, ok := v.(map[string]any); !ok && v != nil {
+			return fmt.Errorf("progress checkpoint must be an object")
+		}
+	}
+	return nil
+}
+
+// ReportProgress records the latest progress of an active job. A report
+// counts as a heartbeat, extending the job's lease.
+func (m *MemoryBackend) ReportProgress(jobID string, update ProgressUpdate) (*MemoryJob, error) {
+	if err := update.validate(); err != nil {
+		return nil, validationError(err.Error())
+	}
+
+	m.mu.Lock()
+	job, ok := m.jobs[jobID]
+	if !ok {
+		m.mu.Unlock()
+		return nil, &RequestError{Status: http.StatusNotFound, Code: "not_found", Message: "Job not found: " + jobID}
+	}
+	l, ok := m.leases[job.ID]
+	if !ok || job.State != StateActive {
+		state := job.State
+		m.mu.Unlock()
+		return nil, &RequestError{
+			Status:  http.StatusConflict,
+			Code:    "invalid_request",
+			Message: fmt.Sprintf("Cannot report progress for job in state %q.", state),
+		}
+	}
+
+	now := time.Now()
+	update.UpdatedAt = formatTime(now)
+	if len(update.Checkpoint) == 0 && job.Progress != nil {
+		// A report without a checkpoint keeps the last one.
+		update.Checkpoint = job.Progress.Checkpoint
+	}
+	job.Progress = &update
+	l.lastBeat = now
+	d, _ := l.deadline(job)
+	job.LeaseExpiresAt = formatTime(d)
+	job = job.clone()
+	onProgress := m.onProgress
+	m.mu.Unlock()
+
+	if onProgress != nil {
+		onProgress(job.ID, update)
+	}
+	m.broadcast(sse.EventJobProgress, job.Queue, job.ID, map[string]any{
+		"job_id":     job.ID,
+		"type":       job.Type,
+		"queue":      job.Queue,
+		"value":      update.Value,
+		"message":    update.Message,
+		"checkpoint": update.Checkpoint,
+	})
+	return job, nil
+}
+
+func (m *MemoryBackend) handleProgress(w http.ResponseWriter, r *http.Request) {
+	var update ProgressUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
+		return
+	}
+
+	job, err := m.ReportProgress(chi.URLParam(r, "id"), update)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"job": job})
+}
@@ -0,0 +1,121 @@
+package backends
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// jobQueue holds the available jobs of one queue as a priority heap.
+// Higher priorities come first; jobs of equal priority come out in the
+// order they were added.
+type jobQueue struct {
+	items []queuedJob
+	index map[string]int // job ID → position in items
+	seq   uint64
+}
+
+type queuedJob struct {
+	job *MemoryJob
+	seq uint64
+}
+
+func newJobQueue() *jobQueue {
+	return &jobQueue{index: make(map[string]int)}
+}
+
+// Len implements heap.Interface.
+func (q *jobQueue) Len() int { return len(q.items) }
+
+// before reports whether a should be fetched before b.
+func before(a, b queuedJob) bool {
+	if a.job.Priority != b.job.Priority {
+		return a.job.Priority > b.job.Priority
+	}
+	return a.seq < b.seq
+}
+
+// Less implements heap.Interface.
+func (q *jobQueue) Less(i, j int) bool { return before(q.items[i], q.items[j]) }
+
+// Swap implements heap.Interface.
+func (q *jobQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.index[q.items[i].job.ID] = i
+	q.index[q.items[j].job.ID] = j
+}
+
+// Push implements heap.Interface. Use add instead.
+func (q *jobQueue) Push(x any) {
+	item := x.(queuedJob)
+	q.index[item.job.ID] = len(q.items)
+	q.items = append(q.items, item)
+}
+
+// Pop implements heap.Interface. Use take instead.
+func (q *jobQueue) Pop() any {
+	last := len(q.items) - 1
+	item := q.items[last]
+	q.items[last] = queuedJob{}
+	q.items = q.items[:last]
+	delete(q.index, item.job.ID)
+	return item
+}
+
+// add queues a job behind any others of the same priority.
+func (q *jobQueue) add(job *MemoryJob) {
+	if _, ok := q.index[job.ID]; ok {
+		return
+	}
+	q.seq++
+	heap.Push(q, queuedJob{job: job, seq: q.seq})
+}
+
+// take removes and returns the next item, keeping its place in line so it
+// can be put back with putBack.
+func (q *jobQueue) take() (queuedJob, bool) {
+	if len(q.items) == 0 {
+		return queuedJob{}, false
+	}
+	return heap.Pop(q).(queuedJob), true
+}
+
+// putBack returns an item obtained from take to its original place.
+func (q *jobQueue) putBack(item queuedJob) {
+	heap.Push(q, item)
+}
+
+// oldest returns the job that has waited longest, regardless of priority,
+// or nil if the queue is empty.
+func (q *jobQueue) oldest() *MemoryJob {
+	if len(q.items) == 0 {
+		return nil
+	}
+	oldest := q.items[0]
+	for _, item := range q.items[1:] {
+		if item.seq < oldest.seq {
+			oldest = item
+		}
+	}
+	return oldest.job
+}
+
+// remove takes a job out of the queue, reporting whether it was there.
+func (q *jobQueue) remove(id string) bool {
+	i, ok := q.index[id]
+	if !ok {
+		return false
+	}
+	heap.Remove(q, i)
+	return true
+}
+
+// jobs returns the queued jobs in the order they would be fetched.
+func (q *jobQueue) jobs() []*MemoryJob {
+	items := append([]queuedJob(nil), q.items...)
+	sort.Slice(items, func(i, j int) bool { return before(items[i], items[j]) })
+	jobs := make([]*MemoryJob, len(items))
+	for i, item := range items {
+		jobs[i] = item.job
+	}
+	return jobs
+}
@@ -0,0 +1,311 @@
+package backends
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Overflow policies for a queue that has reached its max_size.
+const (
+	OverflowReject     = "reject"      // refuse the new job
+	OverflowDropOldest = "drop_oldest" // discard the longest-waiting job to make room
+	OverflowBlock      = "block"       // hold the enqueue until there is room
+)
+
+// maxBlockWait bounds how long an enqueue into a full queue with the
+// "block" overflow policy waits for room before it is rejected.
+const maxBlockWait = 30 * time.Second
+
+// QueueConfig holds per-queue settings. MaxSize counts available jobs and
+// applies to jobs enqueued directly or in a batch; steps of a workflow
+// were admitted with the workflow and are not held to it.
+type QueueConfig struct {
+	DeadLetterQueue string              `json:"dead_letter_queue,omitempty"`
+	Concurrency     int                 `json:"concurrency,omitempty"`
+	RateLimit       *RateLimitPolicy    `json:"rate_limit,omitempty"`
+	MaxSize         int                 `json:"max_size,omitempty"`
+	OverflowPolicy  string              `json:"overflow_policy,omitempty"`
+	DefaultTimeout  int                 `json:"default_timeout,omitempty"` // seconds
+	DefaultRetry    *retryPolicyRequest `json:"default_retry,omitempty"`
+	AllowedJobTypes []string            `json:"allowed_job_types,omitempty"`
+	Retention       *QueueRetention     `json:"retention,omitempty"`
+}
+
+// QueueRetention says how long finished jobs are kept, per final state, as
+// ISO 8601 durations. Jobs without a window are kept forever.
+type QueueRetention struct {
+	Completed string `json:"completed,omitempty"`
+	Discarded string `json:"discarded,omitempty"`
+	Cancelled string `json:"cancelled,omitempty"`
+}
+
+// window returns how long jobs that finished in state are kept, or 0 to
+// keep them forever.
+func (r *QueueRetention) window(state string) time.Duration {
+	if r == nil {
+		return 0
+	}
+	var s string
+	switch state {
+	case StateCompleted:
+		s = r.Completed
+	case StateDiscarded:
+		s = r.Discarded
+	case StateCancelled:
+		s = r.Cancelled
+	}
+	d, _ := parseDuration(s)
+	return d
+}
+
+// validate checks the configuration and fills in defaults.
+func (c *QueueConfig) validate() error {
+	if c.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative")
+	}
+	if c.RateLimit != nil {
+		if err := c.RateLimit.normalize(false); err != nil {
+			return err
+		}
+	}
+	if c.MaxSize < 0 {
+		return fmt.Errorf("max_size must not be negative")
+	}
+	switch c.OverflowPolicy {
+	case "":
+		if c.MaxSize > 0 {
+			c.OverflowPolicy = OverflowReject
+		}
+	case OverflowReject, OverflowDropOldest, OverflowBlock:
+	default:
+		return fmt.Errorf("overflow_policy must be one of reject, drop_oldest, block")
+	}
+	if c.DefaultTimeout < 0 {
+		return fmt.Errorf("default_timeout must not be negative")
+	}
+	if c.DefaultRetry != nil {
+		if _, err := c.DefaultRetry.resolve(); err != nil {
+			return fmt.Errorf("default_retry: %w", err)
+		}
+	}
+	if r := c.Retention; r != nil {
+		for name, s := range map[string]string{"completed": r.Completed, "discarded": r.Discarded, "cancelled": r.Cancelled} {
+			if s == "" {
+				continue
+			}
+			if d, err := parseDuration(s); err != nil || d <= 0 {
+				return fmt.Errorf("retention.%s must be a positive ISO 8601 duration", name)
+			}
+		}
+	}
+	return nil
+}
+
+// allowsType reports whether jobs of the type may be enqueued. An entry
+// ending in "*" allows every type with that prefix.
+func (c *QueueConfig) allowsType(jobType string) bool {
+	if len(c.AllowedJobTypes) == 0 {
+		return true
+	}
+	for _, t := range c.AllowedJobTypes {
+		if t == jobType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "*"); ok && strings.HasPrefix(jobType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueueConfig returns the configuration of the named queue.
+func (m *MemoryBackend) QueueConfig(name string) QueueConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if cfg, ok := m.queueConfigs[name]; ok {
+		return *cfg
+	}
+	return QueueConfig{}
+}
+
+// SetQueueConfig replaces the configuration of the named queue.
+func (m *MemoryBackend) SetQueueConfig(name string, cfg QueueConfig) {
+	m.mu.Lock()
+	m.queueConfigs[name] = &cfg
+	m.mu.Unlock()
+
+	// A larger max_size may let blocked enqueues through.
+	m.signalChange()
+}
+
+// checkRoom reports a queue_full error if the queue has no room for
+// another available job once leaving jobs have left it, unless its
+// overflow policy drops the oldest jobs to make room.
+// Must be called with m.mu held.
+func (m *MemoryBackend) checkRoom(queue string, leaving int) error {
+	cfg, ok := m.queueConfigs[queue]
+	if !ok || cfg.MaxSize <= 0 || cfg.OverflowPolicy == OverflowDropOldest {
+		return nil
+	}
+	if depth := m.queueLen(queue) - leaving; depth >= cfg.MaxSize {
+		return queueFull(queue, depth, cfg.MaxSize)
+	}
+	return nil
+}
+
+// queueFull reports a queue at its max_size.
+func queueFull(queue string, depth, maxSize int) *RequestError {
+	return &RequestError{
+		Status:  http.StatusTooManyRequests,
+		Code:    "queue_full",
+		Message: fmt.Sprintf("Queue %q is full (%d/%d).", queue, depth, maxSize),
+		Details: map[string]any{"queue": queue, "max_size": maxSize},
+	}
+}
+
+// makeRoom enforces the drop_oldest policy of a full queue before a job
+// is added to it, discarding the longest-waiting jobs. Other policies are
+// enforced by checkRoom.
+// Must be called with m.mu held.
+func (m *MemoryBackend) makeRoom(queue string) []transition {
+	cfg, ok := m.queueConfigs[queue]
+	if !ok || cfg.MaxSize <= 0 || cfg.OverflowPolicy != OverflowDropOldest {
+		return nil
+	}
+	q, ok := m.queues[queue]
+	if !ok {
+		return nil
+	}
+
+	var changes []transition
+	for q.Len() >= cfg.MaxSize {
+		oldest := q.oldest()
+		changes = append(changes, m.discardUnstarted(oldest, "queue_overflow",
+			fmt.Sprintf("Dropped to make room in full queue %q.", queue)))
+	}
+	return changes
+}
+
+// isQueueFull reports whether err is the error checkRoom returns for a
+// full queue.
+func isQueueFull(err error) bool {
+	reqErr, ok := err.(*RequestError)
+	return ok && reqErr.Code == "queue_full"
+}
+
+// blocksWhenFull reports whether enqueues into a full queue should wait.
+// Must be called with m.mu held.
+func (m *MemoryBackend) blocksWhenFull(queue string) bool {
+	cfg, ok := m.queueConfigs[queue]
+	return ok && cfg.OverflowPolicy == OverflowBlock
+}
+
+// discardUnstarted discards a job that never became active, recording the
+// reason as its error and as the reason for the change.
+// Must be called with m.mu held.
+func (m *MemoryBackend) discardUnstarted(job *MemoryJob, errType, message string) transition {
+	// Jobs that never ran have no attempt to fail, so this is the one way
+	// to discarded that skips active.
+	fromState := job.State
+	job.State = StateDiscarded
+	job.DiscardedAt = nowFormatted()
+	job.Error, _ = json.Marshal(map[string]any{"type": errType, "message": message})
+	m.removeFromQueue(job)
+	m.releaseRateLimits(job)
+	m.markFinished(job)
+	delete(m.delayed, job.ID)
+	delete(m.expiring, job.ID)
+	return transition{job: job, fromState: fromState, toState: StateDiscarded, reason: errType}
+}
+
+// markFinished records when a job reached a terminal state, so the
+// retention sweep only has to look at finished jobs.
+// Must be called with m.mu held.
+func (m *MemoryBackend) markFinished(job *MemoryJob) {
+	m.finished[job.ID] = time.Now()
+}
+
+// sweepRetention forgets finished jobs that have outlived their queue's
+// retention window. Dead-lettered jobs are kept until they are purged.
+// Jobs retried or replayed since they finished are dropped from the index.
+// Must be called with m.mu held.
+func (m *MemoryBackend) sweepRetention(now time.Time) {
+	retained := false
+	for _, cfg := range m.queueConfigs {
+		if cfg.Retention != nil {
+			retained = true
+			break
+		}
+	}
+	if !retained {
+		return
+	}
+
+	for id, finished := range m.finished {
+		job, ok := m.jobs[id]
+		if !ok || !isTerminalState(job.State) {
+			delete(m.finished, id)
+			continue
+		}
+		cfg, ok := m.queueConfigs[job.Queue]
+		if !ok {
+			continue
+		}
+		window := cfg.Retention.window(job.State)
+		if window == 0 {
+			continue
+		}
+		if _, ok := m.dead[id]; ok {
+			continue
+		}
+		if now.Sub(finished) < window {
+			continue
+		}
+		delete(m.jobs, id)
+		delete(m.finished, id)
+		if e, ok := m.unique[job.UniqueKey]; ok && e.jobID == id {
+			delete(m.unique, job.UniqueKey)
+		}
+	}
+}
+
+// finishedAt returns when a job reached its terminal state.
+func (j *MemoryJob) finishedAt() string {
+	switch j.State {
+	case StateCompleted:
+		return j.CompletedAt
+	case StateCancelled:
+		return j.CancelledAt
+	case StateDiscarded:
+		return j.DiscardedAt
+	}
+	return ""
+}
+
+func (m *MemoryBackend) handleGetQueueConfig(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	writeJSON(w, http.StatusOK, map[string]any{"queue": name, "config": m.QueueConfig(name)})
+}
+
+func (m *MemoryBackend) handlePutQueueConfig(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	var cfg QueueConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
+		return
+	}
+
+	if err := cfg.validate(); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "validation_error", err.Error())
+		return
+	}
+
+	m.SetQueueConfig(name, cfg)
+	writeJSON(w, http.StatusOK, map[string]any{"queue": name, "config": cfg})
+}
@@ -0,0 +1,254 @@
+package backends
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openjobspec/ojs-playground/server/internal/sse"
+)
+
+// OnLimit values of a rate limit policy.
+const (
+	OnLimitWait       = "wait"       // leave the job queued until the limit allows it
+	OnLimitReschedule = "reschedule" // schedule the job for when the limit allows it
+	OnLimitDrop       = "drop"       // discard the job
+)
+
+// rateLimitRetryDelay is how far a job held back by a concurrency limit is
+// rescheduled, since there is no way to know when a slot frees up.
+const rateLimitRetryDelay = time.Second
+
+// maxRateLimitSkips bounds how many waiting jobs one fetch steps over while
+// looking for a job whose rate limit key allows it to run.
+const maxRateLimitSkips = 100
+
+// RateLimitPolicy is the OJS rate limit policy. Jobs sharing a key share
+// its limits, whatever queue they are in.
+type RateLimitPolicy struct {
+	Key         string      `json:"key,omitempty"`
+	Concurrency int         `json:"concurrency,omitempty"`
+	Rate        *RateWindow `json:"rate,omitempty"`
+	Throttle    *RateWindow `json:"throttle,omitempty"`
+	OnLimit     string      `json:"on_limit,omitempty"`
+}
+
+// RateWindow allows Limit job starts per Period (an ISO 8601 duration).
+// As a rate it is a fixed window; as a throttle the starts are spaced
+// evenly across the period.
+type RateWindow struct {
+	Limit  int    `json:"limit"`
+	Period string `json:"period"`
+}
+
+// normalize fills in defaults and validates the policy. Key is required
+// unless the policy belongs to a queue.
+func (p *RateLimitPolicy) normalize(requireKey bool) error {
+	if requireKey && p.Key == "" {
+		return &FieldError{Path: "rate_limit.key", Message: "is required"}
+	}
+	if p.Concurrency < 0 {
+		return &FieldError{Path: "rate_limit.concurrency", Message: "must not be negative"}
+	}
+	for name, w := range map[string]*RateWindow{"rate": p.Rate, "throttle": p.Throttle} {
+		if w == nil {
+			continue
+		}
+		if w.Limit < 1 {
+			return &FieldError{Path: "rate_limit." + name + ".limit", Message: "must be at least 1"}
+		}
+		if d, err := parseDuration(w.Period); err != nil || d <= 0 {
+			return &FieldError{Path: "rate_limit." + name + ".period", Message: "must be a positive ISO 8601 duration"}
+		}
+	}
+	switch p.OnLimit {
+	case "":
+		p.OnLimit = OnLimitWait
+	case OnLimitWait, OnLimitReschedule, OnLimitDrop:
+	default:
+		return &FieldError{Path: "rate_limit.on_limit", Message: "must be one of wait, reschedule, drop"}
+	}
+	return nil
+}
+
+// rateLimiter is the state behind one queue's or key's policy.
+type rateLimiter struct {
+	active        map[string]bool // job IDs holding a concurrency slot
+	held          map[string]bool // job IDs it is holding back
+	windowStart   time.Time
+	windowCount   int
+	nextAllowedAt time.Time
+}
+
+// check reports whether the policy allows another job to start now. If it
+// does not, it returns the reason and how long until it might.
+func (l *rateLimiter) check(p *RateLimitPolicy, now time.Time) (ok bool, reason string, wait time.Duration) {
+	if p.Concurrency > 0 && len(l.active) >= p.Concurrency {
+		return false, fmt.Sprintf("Concurrency limit reached (%d/%d)", len(l.active), p.Concurrency), rateLimitRetryDelay
+	}
+	if p.Rate != nil {
+		period, _ := parseDuration(p.Rate.Period)
+		if !now.Before(l.windowStart.Add(period)) {
+			l.windowStart, l.windowCount = now, 0
+		}
+		if l.windowCount >= p.Rate.Limit {
+			return false, fmt.Sprintf("Rate limit reached (%d/%d per %s)", l.windowCount, p.Rate.Limit, p.Rate.Period),
+				l.windowStart.Add(period).Sub(now)
+		}
+	}
+	if p.Throttle != nil && now.Before(l.nextAllowedAt) {
+		wait := l.nextAllowedAt.Sub(now)
+		return false, fmt.Sprintf("Throttled (next allowed in %dms)", wait.Milliseconds()), wait
+	}
+	return true, "", 0
+}
+
+// acquire records a job starting under the policy.
+func (l *rateLimiter) acquire(p *RateLimitPolicy, jobID string, now time.Time) {
+	l.active[jobID] = true
+	delete(l.held, jobID)
+	l.windowCount++
+	if p.Throttle != nil {
+		period, _ := parseDuration(p.Throttle.Period)
+		l.nextAllowedAt = now.Add(period / time.Duration(p.Throttle.Limit))
+	}
+}
+
+// rateLimitHit describes a job held back by a rate limit.
+type rateLimitHit struct {
+	job     *MemoryJob
+	limiter string
+	policy  *RateLimitPolicy
+	reason  string
+	wait    time.Duration
+	queue   bool // the limit belongs to the job's queue, so it holds back the whole queue
+	repeat  bool // the limiter was already holding the job back
+}
+
+// queueRateLimit returns the effective rate limit policy of a queue, or nil.
+// Must be called with m.mu held.
+func (m *MemoryBackend) queueRateLimit(name string) *RateLimitPolicy {
+	cfg, ok := m.queueConfigs[name]
+	if !ok || (cfg.RateLimit == nil && cfg.Concurrency == 0) {
+		return nil
+	}
+	p := RateLimitPolicy{OnLimit: OnLimitWait}
+	if cfg.RateLimit != nil {
+		p = *cfg.RateLimit
+	}
+	if cfg.Concurrency > 0 {
+		p.Concurrency = cfg.Concurrency
+	}
+	return &p
+}
+
+// rateLimits returns the limiter names and policies that apply to a job.
+// Must be called with m.mu held.
+func (m *MemoryBackend) rateLimits(job *MemoryJob) (names []string, policies []*RateLimitPolicy) {
+	if p := m.queueRateLimit(job.Queue); p != nil {
+		names = append(names, "queue:"+job.Queue)
+		policies = append(policies, p)
+	}
+	if job.RateLimit != nil {
+		names = append(names, "key:"+job.RateLimit.Key)
+		policies = append(policies, job.RateLimit)
+	}
+	return names, policies
+}
+
+// limiter returns the state of the named limiter, creating it on first use.
+// Must be called with m.mu held.
+func (m *MemoryBackend) limiter(name string) *rateLimiter {
+	l, ok := m.rateLimiters[name]
+	if !ok {
+		l = &rateLimiter{active: make(map[string]bool), held: make(map[string]bool)}
+		m.rateLimiters[name] = l
+	}
+	return l
+}
+
+// checkRateLimits returns the first limit holding the job back, or nil if
+// it may start. A job waiting on a limit is checked again on every fetch,
+// but counted as a hit only the first time.
+// Must be called with m.mu held.
+func (m *MemoryBackend) checkRateLimits(job *MemoryJob, now time.Time) *rateLimitHit {
+	names, policies := m.rateLimits(job)
+	for i, name := range names {
+		l := m.limiter(name)
+		if ok, reason, wait := l.check(policies[i], now); !ok {
+			repeat := l.held[job.ID]
+			if !repeat {
+				l.held[job.ID] = true
+				m.rateLimitHits[name]++
+			}
+			return &rateLimitHit{
+				job:     job,
+				limiter: name,
+				policy:  policies[i],
+				reason:  reason,
+				wait:    wait,
+				queue:   name == "queue:"+job.Queue,
+				repeat:  repeat,
+			}
+		}
+	}
+	return nil
+}
+
+// acquireRateLimits records a job starting under every limit that applies.
+// Must be called with m.mu held.
+func (m *MemoryBackend) acquireRateLimits(job *MemoryJob, now time.Time) {
+	names, policies := m.rateLimits(job)
+	for i, name := range names {
+		m.limiter(name).acquire(policies[i], job.ID, now)
+	}
+}
+
+// releaseRateLimits frees the concurrency slots a job held, and forgets
+// that any limiter held it back.
+// Must be called with m.mu held.
+func (m *MemoryBackend) releaseRateLimits(job *MemoryJob) {
+	for _, l := range m.rateLimiters {
+		delete(l.active, job.ID)
+		delete(l.held, job.ID)
+	}
+}
+
+// applyRateLimit carries out the on_limit action for a job that is not in
+// its queue: one fetch took off it, or one being enqueued. It returns the
+// resulting change, if any; waiting jobs are put in their queue by the
+// caller.
+// Must be called with m.mu held.
+func (m *MemoryBackend) applyRateLimit(hit *rateLimitHit, now time.Time) *transition {
+	job := hit.job
+	switch hit.policy.OnLimit {
+	case OnLimitReschedule:
+		// Rate limiting is the one way back from available to scheduled.
+		fromState := job.State
+		runAt := now.Add(hit.wait)
+		job.State = StateScheduled
+		job.ScheduledAt = formatTime(runAt)
+		m.delayed[job.ID] = runAt
+		m.releaseRateLimits(job)
+		return &transition{job: job, fromState: fromState, toState: StateScheduled}
+	case OnLimitDrop:
+		change := m.discardUnstarted(job, "rate_limited", hit.reason)
+		return &change
+	}
+	return nil
+}
+
+// broadcastRateLimitHits announces the jobs a limiter started holding back.
+func (m *MemoryBackend) broadcastRateLimitHits(hits []*rateLimitHit) {
+	for _, h := range hits {
+		if h.repeat {
+			continue
+		}
+		m.broadcast(sse.EventJobRateLimited, h.job.Queue, h.job.ID, map[string]any{
+			"job_id":  h.job.ID,
+			"queue":   h.job.Queue,
+			"limiter": h.limiter,
+			"reason":  h.reason,
+			"action":  h.policy.OnLimit,
+		})
+	}
+}
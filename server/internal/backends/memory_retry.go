@@ -0,0 +1,191 @@
+package backends
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// Exhaustion behaviours for RetryPolicy.OnExhaustion.
+const (
+	OnExhaustionDiscard    = "discard"
+	OnExhaustionDeadLetter = "dead_letter"
+)
+
+// RetryPolicy is the OJS retry policy attached to a job.
+type RetryPolicy struct {
+	MaxAttempts        int      `json:"max_attempts"`
+	InitialInterval    string   `json:"initial_interval"`
+	BackoffCoefficient float64  `json:"backoff_coefficient"`
+	MaxInterval        string   `json:"max_interval"`
+	Jitter             bool     `json:"jitter"`
+	NonRetryableErrors []string `json:"non_retryable_errors,omitempty"`
+	OnExhaustion       string   `json:"on_exhaustion"`
+}
+
+// retryPolicyRequest is the wire form of a retry policy, where every field is optional.
+type retryPolicyRequest struct {
+	MaxAttempts        *int     `json:"max_attempts,omitempty"`
+	InitialInterval    string   `json:"initial_interval,omitempty"`
+	BackoffCoefficient *float64 `json:"backoff_coefficient,omitempty"`
+	MaxInterval        string   `json:"max_interval,omitempty"`
+	Jitter             *bool    `json:"jitter,omitempty"`
+	NonRetryableErrors []string `json:"non_retryable_errors,omitempty"`
+	OnExhaustion       string   `json:"on_exhaustion,omitempty"`
+}
+
+// DefaultRetryPolicy returns the OJS default retry policy.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:        3,
+		InitialInterval:    "PT1S",
+		BackoffCoefficient: 2.0,
+		MaxInterval:        "PT5M",
+		Jitter:             true,
+		OnExhaustion:       OnExhaustionDiscard,
+	}
+}
+
+// resolve merges the request over the defaults and validates the result.
+func (r *retryPolicyRequest) resolve() (*RetryPolicy, error) {
+	p := DefaultRetryPolicy()
+	if r == nil {
+		return &p, nil
+	}
+
+	if r.MaxAttempts != nil {
+		p.MaxAttempts = *r.MaxAttempts
+	}
+	if r.InitialInterval != "" {
+		p.InitialInterval = r.InitialInterval
+	}
+	if r.BackoffCoefficient != nil {
+		p.BackoffCoefficient = *r.BackoffCoefficient
+	}
+	if r.MaxInterval != "" {
+		p.MaxInterval = r.MaxInterval
+	}
+	if r.Jitter != nil {
+		p.Jitter = *r.Jitter
+	}
+	if r.NonRetryableErrors != nil {
+		p.NonRetryableErrors = r.NonRetryableErrors
+	}
+	if r.OnExhaustion != "" {
+		p.OnExhaustion = r.OnExhaustion
+	}
+
+	// 0 disables retry: the first failure discards the job, as with 1.
+	if p.MaxAttempts < 0 {
+		return nil, &FieldError{Path: "retry.max_attempts", Message: "must not be negative"}
+	}
+	initial, err := parseDuration(p.InitialInterval)
+	if err != nil {
+		return nil, &FieldError{Path: "retry.initial_interval", Message: "must be an ISO 8601 duration"}
+	}
+	maxInterval, err := parseDuration(p.MaxInterval)
+	if err != nil {
+		return nil, &FieldError{Path: "retry.max_interval", Message: "must be an ISO 8601 duration"}
+	}
+	if maxInterval < initial {
+		return nil, &FieldError{Path: "retry.max_interval", Message: "must not be less than initial_interval"}
+	}
+	if p.BackoffCoefficient < 1 {
+		return nil, &FieldError{Path: "retry.backoff_coefficient", Message: "must be at least 1"}
+	}
+	if p.OnExhaustion != OnExhaustionDiscard && p.OnExhaustion != OnExhaustionDeadLetter {
+		return nil, &FieldError{Path: "retry.on_exhaustion", Message: fmt.Sprintf("must be %q or %q", OnExhaustionDiscard, OnExhaustionDeadLetter)}
+	}
+
+	return &p, nil
+}
+
+// backoff returns the delay before the given retry (1-indexed):
+// initial_interval * backoff_coefficient^(n-1), capped at max_interval,
+// with optional jitter in [0.5, 1.5).
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	initial, _ := parseDuration(p.InitialInterval)
+	maxInterval, _ := parseDuration(p.MaxInterval)
+
+	delay := time.Duration(float64(initial) * math.Pow(p.BackoffCoefficient, float64(retry-1)))
+	if delay > maxInterval || delay < 0 {
+		delay = maxInterval
+	}
+	if p.Jitter {
+		delay = time.Duration(float64(delay) * (0.5 + rand.Float64()))
+		if delay > maxInterval {
+			delay = maxInterval
+		}
+	}
+	return delay
+}
+
+// isNonRetryable reports whether the nack error's type is listed in
+// non_retryable_errors, either exactly or under a prefix wildcard such as
+// "auth.*".
+func (p *RetryPolicy) isNonRetryable(jobErr json.RawMessage) bool {
+	if p == nil || len(p.NonRetryableErrors) == 0 || jobErr == nil {
+		return false
+	}
+	var e struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(jobErr, &e); err != nil || e.Type == "" {
+		return false
+	}
+	for _, t := range p.NonRetryableErrors {
+		if prefix, ok := strings.CutSuffix(t, "*"); ok && strings.HasPrefix(e.Type, prefix) {
+			return true
+		}
+		if t == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// failJob records a failed attempt of an active job. The job becomes
+// retryable with a backoff delay, or discarded when attempts are exhausted
+// or the error is non-retryable; discarded jobs are dead-lettered when their
+// policy or queue asks for it. With requeue set the backoff is skipped.
+// Must be called with m.mu held.
+func (m *MemoryBackend) failJob(job *MemoryJob, jobErr json.RawMessage, requeue bool, now time.Time) []transition {
+	fromState := job.State
+	targetState := StateRetryable
+	if job.Attempt >= job.MaxAttempts || job.Retry.isNonRetryable(jobErr) {
+		targetState = StateDiscarded
+	}
+
+	job.State = targetState
+	if targetState == StateDiscarded {
+		job.DiscardedAt = nowFormatted()
+		m.markFinished(job)
+	}
+	if jobErr != nil {
+		job.Error = jobErr
+	}
+	m.endLease(job)
+
+	changes := []transition{{job: job, fromState: fromState, toState: targetState}}
+	if targetState == StateDiscarded {
+		if dlq := m.deadLetterFor(job); dlq != "" {
+			m.dead[job.ID] = &DeadLetter{Job: job, DeadLetterQueue: dlq, DeadAt: formatTime(now)}
+		}
+	}
+	if targetState == StateRetryable {
+		var delay time.Duration
+		if !requeue {
+			delay = job.Retry.backoff(job.Attempt)
+		}
+		runAt := now.Add(delay)
+		job.NextAttemptAt = formatTime(runAt)
+		m.delayed[job.ID] = runAt
+		if delay == 0 {
+			changes = append(changes, m.promoteDelayed(runAt)...)
+		}
+	}
+	return changes
+}
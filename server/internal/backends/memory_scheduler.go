@@ -0,0 +1,131 @@
+package backends
+
+import (
+	"context"
+	"time"
+)
+
+// schedulerInterval is how often the background scheduler looks for due jobs.
+const schedulerInterval = 250 * time.Millisecond
+
+// transition records a state change to report via onStateChange once m.mu
+// has been released.
+type transition struct {
+	job       *MemoryJob
+	fromState string
+	toState   string
+	reason    string
+	rateLimit *rateLimitHit // set when a rate limit held back a job being enqueued
+}
+
+// runScheduler runs tick every schedulerInterval, and serves waiting
+// fetches whenever job state changes. Waiters are also served on each tick,
+// for jobs freed by something other than a state change, such as a queue
+// being resumed or a rate limit window passing.
+func (m *MemoryBackend) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		changed := m.changed()
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.tick(now)
+			m.serveWaiters(now)
+		case <-changed:
+			m.serveWaiters(time.Now())
+		}
+	}
+}
+
+// tick runs one pass of all time-based maintenance.
+func (m *MemoryBackend) tick(now time.Time) {
+	m.mu.Lock()
+	changes := m.reapExpired(now)
+	changes = append(changes, m.promoteDelayed(now)...)
+	changes = append(changes, m.expireUnstarted(now)...)
+	m.sweepRetention(now)
+	m.mu.Unlock()
+
+	m.notify(changes)
+}
+
+// promoteDelayed moves scheduled and retryable jobs whose time has come
+// into their queue.
+// Must be called with m.mu held.
+func (m *MemoryBackend) promoteDelayed(now time.Time) []transition {
+	var changes []transition
+	for id, runAt := range m.delayed {
+		if runAt.After(now) {
+			continue
+		}
+		delete(m.delayed, id)
+
+		job, ok := m.jobs[id]
+		if !ok || !isValidTransition(job.State, StateAvailable) {
+			continue
+		}
+
+		fromState := job.State
+		job.State = StateAvailable
+		job.EnqueuedAt = nowFormatted()
+		job.NextAttemptAt = ""
+		m.addToQueue(job)
+		changes = append(changes, transition{job: job, fromState: fromState, toState: StateAvailable})
+	}
+	return changes
+}
+
+// notify advances any workflows the changes belong to and reports the
+// changes, including those caused by workflows, to the onStateChange
+// callback. Jobs that expired are also announced as job:expired, and jobs
+// a rate limit held back on enqueue as job:rate_limited. The jobs are
+// reported as copies, since the scheduler may change them meanwhile.
+// Must be called without m.mu held.
+func (m *MemoryBackend) notify(changes []transition) {
+	for more := changes; len(more) > 0; {
+		more = m.advanceWorkflows(more)
+		changes = append(changes, more...)
+	}
+	if len(changes) == 0 {
+		return
+	}
+	m.signalChange()
+	m.mu.RLock()
+	for i := range changes {
+		changes[i].job = changes[i].job.clone()
+	}
+	m.mu.RUnlock()
+	m.broadcastExpired(changes)
+	var hits []*rateLimitHit
+	for _, c := range changes {
+		if c.rateLimit != nil {
+			hits = append(hits, c.rateLimit)
+		}
+	}
+	m.broadcastRateLimitHits(hits)
+	if m.onStateChange == nil {
+		return
+	}
+	for _, c := range changes {
+		m.onStateChange(c.job, c.fromState, c.toState, c.reason)
+	}
+}
+
+// changed returns a channel that is closed the next time job state
+// changes, for callers waiting on the backend without holding m.mu.
+func (m *MemoryBackend) changed() <-chan struct{} {
+	m.changeMu.Lock()
+	defer m.changeMu.Unlock()
+	return m.changeCh
+}
+
+// signalChange wakes everything waiting on changed.
+func (m *MemoryBackend) signalChange() {
+	m.changeMu.Lock()
+	close(m.changeCh)
+	m.changeCh = make(chan struct{})
+	m.changeMu.Unlock()
+}
@@ -0,0 +1,337 @@
+package backends
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotVersion is bumped whenever the snapshot format changes in a way
+// older code cannot read.
+const snapshotVersion = 1
+
+// AutosaveSnapshot is the snapshot written on shutdown and restored on
+// start.
+const AutosaveSnapshot = "autosave"
+
+// ErrSnapshotNotFound is returned when loading a snapshot that does not
+// exist.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+var snapshotNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// snapshot is the serialized state of a MemoryBackend.
+type snapshot struct {
+	Version      int                      `json:"version"`
+	TakenAt      time.Time                `json:"taken_at"`
+	Jobs         []*MemoryJob             `json:"jobs"`
+	Queues       map[string][]string      `json:"queues"`  // queue name → job IDs in fetch order
+	Delayed      map[string]time.Time     `json:"delayed"` // job ID → time it becomes available
+	Leases       map[string]snapshotLease `json:"leases"`
+	Unique       map[string]snapshotEntry `json:"unique"`
+	Paused       bool                     `json:"paused"`
+	PausedQueues []string                 `json:"paused_queues"`
+	QueueConfigs map[string]*QueueConfig  `json:"queue_configs"`
+	DeadLetters  []*DeadLetter            `json:"dead_letters"`
+	Workflows    []snapshotWorkflow       `json:"workflows"`
+}
+
+type snapshotLease struct {
+	StartedAt time.Time `json:"started_at"`
+	LastBeat  time.Time `json:"last_beat"`
+}
+
+type snapshotEntry struct {
+	JobID     string    `json:"job_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// snapshotWorkflow carries the prepared jobs of steps that have not been
+// enqueued yet alongside the workflow.
+type snapshotWorkflow struct {
+	*Workflow
+	Pending map[string]snapshotPending `json:"pending,omitempty"` // step job ID → prepared job
+}
+
+type snapshotPending struct {
+	Job   *MemoryJob `json:"job"`
+	RunAt time.Time  `json:"run_at,omitempty"`
+}
+
+// WriteSnapshot writes the backend's jobs, queues, timers, pause state,
+// dead letters and workflows to w as JSON.
+func (m *MemoryBackend) WriteSnapshot(w io.Writer) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snap := snapshot{
+		Version:      snapshotVersion,
+		TakenAt:      time.Now().UTC(),
+		Jobs:         make([]*MemoryJob, 0, len(m.jobs)),
+		Queues:       make(map[string][]string, len(m.queues)),
+		Delayed:      m.delayed,
+		Leases:       make(map[string]snapshotLease, len(m.leases)),
+		Unique:       make(map[string]snapshotEntry, len(m.unique)),
+		Paused:       m.paused,
+		PausedQueues: m.pausedQueueNames(),
+		QueueConfigs: m.queueConfigs,
+		DeadLetters:  make([]*DeadLetter, 0, len(m.dead)),
+	}
+	for _, job := range m.jobs {
+		snap.Jobs = append(snap.Jobs, job)
+	}
+	sort.Slice(snap.Jobs, func(i, j int) bool { return snap.Jobs[i].ID < snap.Jobs[j].ID })
+	for name, q := range m.queues {
+		for _, job := range q.jobs() {
+			snap.Queues[name] = append(snap.Queues[name], job.ID)
+		}
+	}
+	for id, l := range m.leases {
+		snap.Leases[id] = snapshotLease{StartedAt: l.startedAt, LastBeat: l.lastBeat}
+	}
+	for key, e := range m.unique {
+		snap.Unique[key] = snapshotEntry{JobID: e.jobID, CreatedAt: e.createdAt}
+	}
+	for _, dl := range m.dead {
+		snap.DeadLetters = append(snap.DeadLetters, dl)
+	}
+	for _, wf := range m.workflows {
+		sw := snapshotWorkflow{Workflow: wf, Pending: make(map[string]snapshotPending)}
+		steps := append([]*WorkflowStep(nil), wf.Steps...)
+		for _, s := range wf.Callbacks {
+			steps = append(steps, s)
+		}
+		for _, s := range steps {
+			if s.job != nil {
+				sw.Pending[s.JobID] = snapshotPending{Job: s.job, RunAt: s.runAt}
+			}
+		}
+		snap.Workflows = append(snap.Workflows, sw)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(snap)
+}
+
+// RestoreSnapshot replaces the backend's state with a snapshot written by
+// WriteSnapshot. No state change callbacks are made for restored jobs.
+func (m *MemoryBackend) RestoreSnapshot(r io.Reader) error {
+	var snap snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+
+	jobs := make(map[string]*MemoryJob, len(snap.Jobs))
+	for _, job := range snap.Jobs {
+		jobs[job.ID] = job
+	}
+	queues := make(map[string]*jobQueue, len(snap.Queues))
+	for name, ids := range snap.Queues {
+		q := newJobQueue()
+		for _, id := range ids {
+			if job, ok := jobs[id]; ok && job.State == StateAvailable {
+				q.add(job)
+			}
+		}
+		queues[name] = q
+	}
+	delayed := make(map[string]time.Time, len(snap.Delayed))
+	for id, t := range snap.Delayed {
+		delayed[id] = t
+	}
+	expiring := make(map[string]time.Time)
+	finished := make(map[string]time.Time)
+	for id, job := range jobs {
+		if t, ok := job.expiry(); ok && !isTerminalState(job.State) {
+			expiring[id] = t
+		}
+		if t, err := time.Parse(time.RFC3339, job.finishedAt()); err == nil && isTerminalState(job.State) {
+			finished[id] = t
+		}
+	}
+	leases := make(map[string]*lease, len(snap.Leases))
+	for id, l := range snap.Leases {
+		leases[id] = &lease{startedAt: l.StartedAt, lastBeat: l.LastBeat}
+	}
+	unique := make(map[string]uniqueEntry, len(snap.Unique))
+	for key, e := range snap.Unique {
+		unique[key] = uniqueEntry{jobID: e.JobID, createdAt: e.CreatedAt}
+	}
+	pausedQueues := make(map[string]bool, len(snap.PausedQueues))
+	for _, name := range snap.PausedQueues {
+		pausedQueues[name] = true
+	}
+	queueConfigs := snap.QueueConfigs
+	if queueConfigs == nil {
+		queueConfigs = make(map[string]*QueueConfig)
+	}
+	dead := make(map[string]*DeadLetter, len(snap.DeadLetters))
+	for _, dl := range snap.DeadLetters {
+		if dl.Job == nil {
+			continue
+		}
+		// Dead letters share their job with the job table.
+		if job, ok := jobs[dl.Job.ID]; ok {
+			dl.Job = job
+		}
+		dead[dl.Job.ID] = dl
+	}
+	workflows := make(map[string]*Workflow, len(snap.Workflows))
+	for _, sw := range snap.Workflows {
+		if sw.Workflow == nil {
+			continue
+		}
+		steps := append([]*WorkflowStep(nil), sw.Steps...)
+		for _, s := range sw.Callbacks {
+			steps = append(steps, s)
+		}
+		for _, s := range steps {
+			if p, ok := sw.Pending[s.JobID]; ok {
+				s.job, s.runAt = p.Job, p.RunAt
+			}
+		}
+		workflows[sw.ID] = sw.Workflow
+	}
+
+	m.mu.Lock()
+	m.jobs = jobs
+	m.queues = queues
+	m.delayed = delayed
+	m.expiring = expiring
+	m.finished = finished
+	m.leases = leases
+	m.unique = unique
+	m.paused = snap.Paused
+	m.pausedQueues = pausedQueues
+	m.queueConfigs = queueConfigs
+	m.dead = dead
+	m.workflows = workflows
+	m.rateLimiters = make(map[string]*rateLimiter)
+	now := time.Now()
+	for id := range leases {
+		if job, ok := jobs[id]; ok {
+			m.acquireRateLimits(job, now)
+		}
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// SnapshotInfo describes a saved snapshot.
+type SnapshotInfo struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// SnapshotStore saves and loads named MemoryBackend snapshots as files in
+// a directory.
+type SnapshotStore struct {
+	dir     string
+	backend *MemoryBackend
+}
+
+// NewSnapshotStore creates a SnapshotStore keeping snapshots of backend
+// in dir. The directory is created on first save.
+func NewSnapshotStore(dir string, backend *MemoryBackend) *SnapshotStore {
+	return &SnapshotStore{dir: dir, backend: backend}
+}
+
+// ValidSnapshotName reports whether name can be used as a snapshot name.
+func ValidSnapshotName(name string) bool {
+	return snapshotNamePattern.MatchString(name)
+}
+
+func (s *SnapshotStore) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// Save writes the backend's current state to the named snapshot,
+// replacing any existing snapshot of that name.
+func (s *SnapshotStore) Save(name string) (*SnapshotInfo, error) {
+	if !ValidSnapshotName(name) {
+		return nil, fmt.Errorf("invalid snapshot name %q", name)
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("create snapshot dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := s.backend.WriteSnapshot(tmp); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(name)); err != nil {
+		return nil, fmt.Errorf("save snapshot: %w", err)
+	}
+	return s.info(name)
+}
+
+// Load restores the backend from the named snapshot.
+func (s *SnapshotStore) Load(name string) error {
+	if !ValidSnapshotName(name) {
+		return ErrSnapshotNotFound
+	}
+	f, err := os.Open(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrSnapshotNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("open snapshot: %w", err)
+	}
+	defer f.Close()
+	return s.backend.RestoreSnapshot(f)
+}
+
+// List returns the saved snapshots, newest first.
+func (s *SnapshotStore) List() ([]*SnapshotInfo, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots: %w", err)
+	}
+
+	var infos []*SnapshotInfo
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok || !ValidSnapshotName(name) {
+			continue
+		}
+		info, err := s.info(name)
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].SavedAt.After(infos[j].SavedAt) })
+	return infos, nil
+}
+
+func (s *SnapshotStore) info(name string) (*SnapshotInfo, error) {
+	fi, err := os.Stat(s.path(name))
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotInfo{Name: name, Size: fi.Size(), SavedAt: fi.ModTime().UTC()}, nil
+}
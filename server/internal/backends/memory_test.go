@@ -2,13 +2,19 @@ package backends
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/openjobspec/ojs-playground/server/internal/chaos"
+	"github.com/openjobspec/ojs-playground/server/internal/sse"
 )
 
 func newTestBackend() *MemoryBackend {
@@ -338,9 +344,12 @@ func TestNackJobRetryable(t *testing.T) {
 	}
 	json.Unmarshal(rr.Body.Bytes(), &resp)
 
-	// After nack with retries remaining, job becomes available (auto-requeue)
-	if resp.Job.State != StateAvailable {
-		t.Errorf("expected state available (re-queued), got %s", resp.Job.State)
+	// After nack with retries remaining, job waits out its backoff
+	if resp.Job.State != StateRetryable {
+		t.Errorf("expected state retryable, got %s", resp.Job.State)
+	}
+	if resp.Job.NextAttemptAt == "" {
+		t.Error("expected next_attempt_at to be set")
 	}
 }
 
@@ -361,6 +370,7 @@ func TestNackJobExhausted(t *testing.T) {
 			t.Fatalf("iteration %d: expected a job, got none", i)
 		}
 		doRequest(t, r, "POST", "/workers/nack", map[string]any{"job_id": fetchResp.Jobs[0].ID})
+		mb.tick(time.Now().Add(time.Hour))
 	}
 
 	// Get job — should be discarded
@@ -436,7 +446,7 @@ func TestListQueues(t *testing.T) {
 
 func TestStateChangeCallback(t *testing.T) {
 	var calls []string
-	mb := NewMemoryBackend(func(job *MemoryJob, from, to string) {
+	mb := NewMemoryBackend(func(job *MemoryJob, from, to, reason string) {
 		calls = append(calls, from+"→"+to)
 	})
 	r := mb.Router()
@@ -458,3 +468,2359 @@ func TestHealth(t *testing.T) {
 		t.Fatalf("expected 200, got %d", rr.Code)
 	}
 }
+
+func TestScheduledJobPromotion(t *testing.T) {
+	var calls []string
+	mb := NewMemoryBackend(func(job *MemoryJob, from, to, reason string) {
+		calls = append(calls, from+"→"+to)
+	})
+	mb.Close() // drive the scheduler by hand
+	r := mb.Router()
+
+	runAt := time.Now().Add(time.Minute)
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "cron.task",
+		"args": []any{},
+		"options": map[string]any{
+			"scheduled_at": runAt.UTC().Format(time.RFC3339),
+		},
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rr.Code)
+	}
+
+	// Not due yet
+	mb.tick(time.Now())
+	rr = doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}})
+	var fetchResp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &fetchResp)
+	if len(fetchResp.Jobs) != 0 {
+		t.Fatalf("expected no jobs before scheduled_at, got %d", len(fetchResp.Jobs))
+	}
+
+	mb.tick(runAt.Add(time.Second))
+	rr = doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}})
+	json.Unmarshal(rr.Body.Bytes(), &fetchResp)
+	if len(fetchResp.Jobs) != 1 {
+		t.Fatalf("expected 1 job after scheduled_at, got %d", len(fetchResp.Jobs))
+	}
+
+	want := []string{"→scheduled", "scheduled→available", "available→active"}
+	if len(calls) != len(want) {
+		t.Fatalf("expected callbacks %v, got %v", want, calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("callback %d: expected %s, got %s", i, want[i], calls[i])
+		}
+	}
+}
+
+func TestScheduledJobInPastIsAvailable(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type":    "cron.task",
+		"args":    []any{},
+		"options": map[string]any{"scheduled_at": "2000-01-01T00:00:00Z"},
+	})
+
+	var resp struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+
+	if resp.Job.State != StateAvailable {
+		t.Errorf("expected state available, got %s", resp.Job.State)
+	}
+}
+
+func TestCreateJobInvalidScheduledAt(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type":    "cron.task",
+		"options": map[string]any{"scheduled_at": "tomorrow"},
+	})
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestNackJobBackoff(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "email.send",
+		"args": []any{},
+		"options": map[string]any{
+			"retry": map[string]any{
+				"max_attempts":     5,
+				"initial_interval": "PT10S",
+				"jitter":           false,
+			},
+		},
+	})
+	rr := doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}})
+	var fetchResp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &fetchResp)
+	if fetchResp.Jobs[0].MaxAttempts != 5 {
+		t.Errorf("expected max_attempts 5, got %d", fetchResp.Jobs[0].MaxAttempts)
+	}
+
+	doRequest(t, r, "POST", "/workers/nack", map[string]any{"job_id": fetchResp.Jobs[0].ID})
+
+	// Still waiting after 5s
+	mb.tick(time.Now().Add(5 * time.Second))
+	rr = doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}})
+	json.Unmarshal(rr.Body.Bytes(), &fetchResp)
+	if len(fetchResp.Jobs) != 0 {
+		t.Fatalf("expected no jobs during backoff, got %d", len(fetchResp.Jobs))
+	}
+
+	// Available after 10s
+	mb.tick(time.Now().Add(11 * time.Second))
+	rr = doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}})
+	json.Unmarshal(rr.Body.Bytes(), &fetchResp)
+	if len(fetchResp.Jobs) != 1 {
+		t.Fatalf("expected 1 job after backoff, got %d", len(fetchResp.Jobs))
+	}
+	if fetchResp.Jobs[0].Attempt != 2 {
+		t.Errorf("expected attempt 2, got %d", fetchResp.Jobs[0].Attempt)
+	}
+}
+
+func TestNackJobNonRetryableError(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "email.send",
+		"args": []any{},
+		"options": map[string]any{
+			"retry": map[string]any{"non_retryable_errors": []string{"validation"}},
+		},
+	})
+	rr := doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}})
+	var fetchResp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &fetchResp)
+
+	rr = doRequest(t, r, "POST", "/workers/nack", map[string]any{
+		"job_id": fetchResp.Jobs[0].ID,
+		"error":  map[string]any{"type": "validation", "message": "bad input"},
+	})
+
+	var resp struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+
+	if resp.Job.State != StateDiscarded {
+		t.Errorf("expected state discarded, got %s", resp.Job.State)
+	}
+}
+
+func TestCreateJobInvalidRetryPolicy(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "email.send",
+		"options": map[string]any{
+			"retry": map[string]any{"initial_interval": "1s"},
+		},
+	})
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestRetryPolicyMatchesSchema(t *testing.T) {
+	zero := 0
+	p, err := (&retryPolicyRequest{MaxAttempts: &zero}).resolve()
+	if err != nil || p.MaxAttempts != 0 {
+		t.Errorf("expected max_attempts 0 to be accepted, got %+v %v", p, err)
+	}
+
+	_, err = (&retryPolicyRequest{InitialInterval: "PT10S", MaxInterval: "PT5S"}).resolve()
+	if fe, ok := err.(*FieldError); !ok || fe.Path != "retry.max_interval" {
+		t.Errorf("expected max_interval below initial_interval to be rejected, got %v", err)
+	}
+	if _, err := (&retryPolicyRequest{InitialInterval: "PT5S", MaxInterval: "PT5S"}).resolve(); err != nil {
+		t.Errorf("expected equal intervals to be accepted, got %v", err)
+	}
+}
+
+func TestNonRetryableErrorWildcard(t *testing.T) {
+	p := &RetryPolicy{NonRetryableErrors: []string{"auth.*", "billing.card_declined"}}
+	cases := map[string]bool{
+		"auth.expired":          true,
+		"auth.token.revoked":    true,
+		"billing.card_declined": true,
+		"billing.timeout":       false,
+		"authz":                 false,
+		"auth":                  false,
+	}
+	for typ, want := range cases {
+		jobErr, _ := json.Marshal(map[string]string{"type": typ})
+		if got := p.isNonRetryable(jobErr); got != want {
+			t.Errorf("%s: expected non-retryable %v, got %v", typ, want, got)
+		}
+	}
+}
+
+func TestNackJobNoRetries(t *testing.T) {
+	mb := newTestBackend()
+	ctx := context.Background()
+
+	zero := 0
+	job, _ := mb.EnqueueJob(ctx, &EnqueueRequest{Type: "once", Options: &EnqueueOptions{Retry: &retryPolicyRequest{MaxAttempts: &zero}}})
+	mb.Fetch(ctx, FetchRequest{})
+	failed, err := mb.Nack(ctx, NackRequest{JobID: job.ID})
+	if err != nil || failed.State != StateDiscarded {
+		t.Errorf("expected the first failure to discard the job, got %+v %v", failed, err)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := DefaultRetryPolicy()
+	p.Jitter = false
+	p.MaxInterval = "PT3S"
+
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w {
+			t.Errorf("retry %d: expected %s, got %s", i+1, w, got)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"PT1S":    time.Second,
+		"PT0.5S":  500 * time.Millisecond,
+		"PT5M":    5 * time.Minute,
+		"PT1H30M": 90 * time.Minute,
+		"P1D":     24 * time.Hour,
+	}
+	for in, want := range cases {
+		got, err := parseDuration(in)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: expected %s, got %s", in, want, got)
+		}
+	}
+
+	for _, in := range []string{"", "P", "PT", "1s", "P1DT"} {
+		if _, err := parseDuration(in); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
+
+func fetchOne(t *testing.T, r chi.Router) *MemoryJob {
+	t.Helper()
+	rr := doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}})
+	var resp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(resp.Jobs))
+	}
+	return &resp.Jobs[0]
+}
+
+func TestExecutionTimeoutReaped(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type":    "report.generate",
+		"args":    []any{},
+		"options": map[string]any{"timeout": 30},
+	})
+	job := fetchOne(t, r)
+	if job.LeaseExpiresAt == "" {
+		t.Error("expected lease_expires_at to be set")
+	}
+
+	mb.tick(time.Now().Add(10 * time.Second))
+	if got, _ := mb.GetJob(job.ID); got.State != StateActive {
+		t.Fatalf("expected job still active, got %s", got.State)
+	}
+
+	mb.tick(time.Now().Add(31 * time.Second))
+	got, _ := mb.GetJob(job.ID)
+	if got.State != StateRetryable {
+		t.Fatalf("expected state retryable after timeout, got %s", got.State)
+	}
+	var jobErr struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal(got.Error, &jobErr)
+	if jobErr.Type != "timeout" {
+		t.Errorf("expected error type timeout, got %q", jobErr.Type)
+	}
+
+	// Acking after the lease was reaped must fail
+	rr := doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": job.ID})
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rr.Code)
+	}
+}
+
+func TestHeartbeatExtendsLease(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "report.generate",
+		"args": []any{},
+		"options": map[string]any{
+			"timeout": map[string]any{"heartbeat": 10, "heartbeat_grace": 5},
+		},
+	})
+	job := fetchOne(t, r)
+
+	// Rewind the last heartbeat to simulate 12s passing, then heartbeat
+	mb.mu.Lock()
+	mb.leases[job.ID].lastBeat = time.Now().Add(-12 * time.Second)
+	mb.mu.Unlock()
+	rr := doRequest(t, r, "POST", "/workers/heartbeat", map[string]any{"job_id": job.ID})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	mb.tick(time.Now().Add(14 * time.Second))
+	if got, _ := mb.GetJob(job.ID); got.State != StateActive {
+		t.Fatalf("expected job still active after heartbeat, got %s", got.State)
+	}
+
+	mb.tick(time.Now().Add(16 * time.Second))
+	if got, _ := mb.GetJob(job.ID); got.State != StateRetryable {
+		t.Errorf("expected state retryable after missed heartbeat, got %s", got.State)
+	}
+}
+
+func TestHeartbeatInactiveJob(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+	job := createJob(t, r, "email.send")
+
+	rr := doRequest(t, r, "POST", "/workers/heartbeat", map[string]any{"job_id": job.ID})
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rr.Code)
+	}
+}
+
+func createUniqueJob(t *testing.T, r chi.Router, args []any, unique map[string]any) *httptest.ResponseRecorder {
+	t.Helper()
+	return doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type":    "email.send",
+		"args":    args,
+		"options": map[string]any{"unique": unique},
+	})
+}
+
+func TestUniqueReject(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	unique := map[string]any{"keys": []string{"type", "args"}}
+	rr := createUniqueJob(t, r, []any{"a@test.com"}, unique)
+	var first struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &first)
+
+	rr = createUniqueJob(t, r, []any{"a@test.com"}, unique)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+	var errResp struct {
+		Error struct {
+			Code          string `json:"code"`
+			ExistingJobID string `json:"existing_job_id"`
+		} `json:"error"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &errResp)
+	if errResp.Error.ExistingJobID != first.Job.ID {
+		t.Errorf("expected existing_job_id %s, got %s", first.Job.ID, errResp.Error.ExistingJobID)
+	}
+
+	// Different args are not duplicates
+	rr = createUniqueJob(t, r, []any{"b@test.com"}, unique)
+	if rr.Code != http.StatusCreated {
+		t.Errorf("expected 201, got %d", rr.Code)
+	}
+}
+
+func TestUniqueIgnore(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	unique := map[string]any{"on_conflict": "ignore"}
+	createUniqueJob(t, r, []any{1}, unique)
+	rr := createUniqueJob(t, r, []any{2}, unique)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if n := len(mb.ListJobs()); n != 1 {
+		t.Errorf("expected 1 job, got %d", n)
+	}
+}
+
+func TestUniqueReplace(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	unique := map[string]any{"on_conflict": "replace"}
+	rr := createUniqueJob(t, r, []any{1}, unique)
+	var first struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &first)
+
+	rr = createUniqueJob(t, r, []any{2}, unique)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rr.Code)
+	}
+
+	old, _ := mb.GetJob(first.Job.ID)
+	if old.State != StateCancelled {
+		t.Errorf("expected replaced job to be cancelled, got %s", old.State)
+	}
+}
+
+func TestUniqueReplaceExceptSchedule(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "email.send",
+		"args": []any{1},
+		"options": map[string]any{
+			"scheduled_at": "2030-01-01T00:00:00Z",
+			"unique":       map[string]any{"on_conflict": "replace_except_schedule"},
+		},
+	})
+	rr := createUniqueJob(t, r, []any{2}, map[string]any{"on_conflict": "replace_except_schedule"})
+
+	var resp struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+
+	if resp.Job.State != StateScheduled {
+		t.Errorf("expected state scheduled, got %s", resp.Job.State)
+	}
+	if resp.Job.ScheduledAt != "2030-01-01T00:00:00Z" {
+		t.Errorf("expected scheduled_at preserved, got %q", resp.Job.ScheduledAt)
+	}
+	if string(resp.Job.Args) != "[2]" {
+		t.Errorf("expected new args, got %s", resp.Job.Args)
+	}
+}
+
+func TestUniqueStatesAndArgsKeys(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	unique := map[string]any{
+		"keys":      []string{"args"},
+		"args_keys": []string{"user_id"},
+	}
+	createUniqueJob(t, r, []any{map[string]any{"user_id": 1, "nonce": "a"}}, unique)
+
+	// Differs only in a key outside args_keys
+	rr := createUniqueJob(t, r, []any{map[string]any{"user_id": 1, "nonce": "b"}}, unique)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+
+	// Once the first job completes it no longer blocks
+	job := fetchOne(t, r)
+	doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": job.ID})
+	rr = createUniqueJob(t, r, []any{map[string]any{"user_id": 1, "nonce": "c"}}, unique)
+	if rr.Code != http.StatusCreated {
+		t.Errorf("expected 201, got %d", rr.Code)
+	}
+}
+
+func TestUniqueInvalidPolicy(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	rr := createUniqueJob(t, r, []any{}, map[string]any{"keys": []string{"meta"}})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestPauseQueueStopsFetch(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+	createJob(t, r, "email.send")
+
+	rr := doRequest(t, r, "POST", "/queues/default/pause", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	rr = doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}})
+	var resp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Jobs) != 0 {
+		t.Fatalf("expected no jobs from paused queue, got %d", len(resp.Jobs))
+	}
+
+	rr = doRequest(t, r, "GET", "/queues", nil)
+	var queuesResp struct {
+		Queues []struct {
+			Name   string `json:"name"`
+			Paused bool   `json:"paused"`
+		} `json:"queues"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &queuesResp)
+	if len(queuesResp.Queues) != 1 || !queuesResp.Queues[0].Paused {
+		t.Errorf("expected default queue listed as paused, got %+v", queuesResp.Queues)
+	}
+
+	doRequest(t, r, "POST", "/queues/default/resume", nil)
+	fetchOne(t, r)
+}
+
+func TestPauseBackend(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+	createJob(t, r, "email.send")
+
+	mb.Pause()
+	stats, _ := mb.Stats(context.Background())
+	if !stats.Paused {
+		t.Error("expected stats to report paused")
+	}
+
+	rr := doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}})
+	var resp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Jobs) != 0 {
+		t.Fatalf("expected no jobs while backend paused, got %d", len(resp.Jobs))
+	}
+
+	mb.Resume()
+	fetchOne(t, r)
+}
+
+func TestChaosPausedQueue(t *testing.T) {
+	mb := newTestBackend()
+	cfg := chaos.NewConfig()
+	cfg.Update(chaos.UpdateRequest{PausedQueues: []string{"default"}})
+	mb.SetChaosConfig(cfg)
+	r := mb.Router()
+	createJob(t, r, "email.send")
+
+	rr := doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}})
+	var resp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Jobs) != 0 {
+		t.Errorf("expected no jobs from chaos-paused queue, got %d", len(resp.Jobs))
+	}
+}
+
+func TestPauseBroadcastsEvents(t *testing.T) {
+	mb := newTestBackend()
+	b := sse.NewBroadcaster()
+	mb.SetBroadcaster(b)
+	sub, unsub := b.Subscribe(sse.SubscribeFilter{})
+	defer unsub()
+
+	mb.PauseQueue("email")
+	mb.ResumeQueue("email")
+
+	for _, want := range []string{sse.EventQueuePaused, sse.EventQueueResumed} {
+		select {
+		case ev := <-sub.Ch:
+			if ev.Type != want || ev.Queue != "email" {
+				t.Errorf("expected %s for email, got %s for %q", want, ev.Type, ev.Queue)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+}
+
+// exhaustJob fetches and nacks the only job in the default queue until it
+// runs out of attempts.
+func exhaustJob(t *testing.T, mb *MemoryBackend, r chi.Router) *MemoryJob {
+	t.Helper()
+	var job *MemoryJob
+	for {
+		job = fetchOne(t, r)
+		doRequest(t, r, "POST", "/workers/nack", map[string]any{"job_id": job.ID})
+		if job.Attempt >= job.MaxAttempts {
+			return job
+		}
+		mb.tick(time.Now().Add(time.Hour))
+	}
+}
+
+func TestDeadLetterOnExhaustion(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "email.send",
+		"args": []any{},
+		"options": map[string]any{
+			"retry": map[string]any{"max_attempts": 2, "on_exhaustion": "dead_letter"},
+		},
+	})
+	job := exhaustJob(t, mb, r)
+
+	rr := doRequest(t, r, "GET", "/dead", nil)
+	var listResp struct {
+		Dead []struct {
+			Job             MemoryJob `json:"job"`
+			DeadLetterQueue string    `json:"dead_letter_queue"`
+		} `json:"dead"`
+		Total int `json:"total"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &listResp)
+	if listResp.Total != 1 || listResp.Dead[0].Job.ID != job.ID {
+		t.Fatalf("expected job %s in dead letters, got %+v", job.ID, listResp)
+	}
+	if listResp.Dead[0].DeadLetterQueue != "dead" {
+		t.Errorf("expected dead letter queue dead, got %s", listResp.Dead[0].DeadLetterQueue)
+	}
+
+	rr = doRequest(t, r, "POST", "/dead/"+job.ID+"/retry", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	replayed := fetchOne(t, r)
+	if replayed.DiscardedAt != "" {
+		t.Errorf("expected the replayed job to no longer be discarded, got discarded_at %s", replayed.DiscardedAt)
+	}
+	if replayed.ID != job.ID || replayed.Attempt != 1 {
+		t.Errorf("expected replayed job %s at attempt 1, got %s at %d", job.ID, replayed.ID, replayed.Attempt)
+	}
+	if _, ok := mb.GetDeadLetter(job.ID); ok {
+		t.Error("expected dead letter to be removed after replay")
+	}
+}
+
+func TestDeadLetterDiscardByDefault(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	createJob(t, r, "email.send")
+	exhaustJob(t, mb, r)
+
+	if _, total := mb.ListDeadLetters(DeadLetterFilter{}); total != 0 {
+		t.Errorf("expected no dead letters, got %d", total)
+	}
+}
+
+func TestDeadLetterQueueConfig(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	rr := doRequest(t, r, "PUT", "/queues/default/config", map[string]any{"dead_letter_queue": "default-dlq"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	createJob(t, r, "email.send")
+	exhaustJob(t, mb, r)
+
+	dead, _ := mb.ListDeadLetters(DeadLetterFilter{DeadLetterQueue: "default-dlq"})
+	if len(dead) != 1 {
+		t.Fatalf("expected 1 dead letter in default-dlq, got %d", len(dead))
+	}
+
+	rr = doRequest(t, r, "DELETE", "/dead", map[string]any{"queue": "default-dlq"})
+	var purgeResp struct {
+		Purged int `json:"purged"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &purgeResp)
+	if purgeResp.Purged != 1 {
+		t.Errorf("expected 1 purged, got %d", purgeResp.Purged)
+	}
+}
+
+func TestDeadLetterQueueHonoursDiscard(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	doRequest(t, r, "PUT", "/queues/default/config", map[string]any{"dead_letter_queue": "default-dlq"})
+	doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type":    "email.send",
+		"args":    []any{},
+		"options": map[string]any{"retry": map[string]any{"max_attempts": 1, "on_exhaustion": "discard"}},
+	})
+	exhaustJob(t, mb, r)
+
+	if _, total := mb.ListDeadLetters(DeadLetterFilter{}); total != 0 {
+		t.Errorf("expected an explicit discard to skip the dead letter queue, got %d dead letters", total)
+	}
+}
+
+func TestDeadLetterBulkReplay(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+	mb.SetQueueConfig("default", QueueConfig{DeadLetterQueue: "dlq"})
+
+	for i := 0; i < 2; i++ {
+		createJob(t, r, "email.send")
+		exhaustJob(t, mb, r)
+	}
+
+	rr := doRequest(t, r, "POST", "/dead/retry", map[string]any{})
+	var resp struct {
+		Replayed int `json:"replayed"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Replayed != 2 {
+		t.Errorf("expected 2 replayed, got %d", resp.Replayed)
+	}
+}
+
+func TestDeadLetterSelection(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+	mb.SetQueueConfig("default", QueueConfig{DeadLetterQueue: "dlq"})
+
+	for i := 0; i < 3; i++ {
+		createJob(t, r, "email.send")
+		exhaustJob(t, mb, r)
+	}
+
+	// An empty body of unknown length is an empty selection.
+	req := httptest.NewRequest("POST", "/dead/retry", io.MultiReader())
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"replayed":3`) {
+		t.Fatalf("expected all 3 replayed, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	for i := 0; i < 3; i++ {
+		exhaustJob(t, mb, r)
+	}
+	if rr := doRequest(t, r, "DELETE", "/dead", map[string]any{}); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected a purge selecting nothing to be refused, got %d", rr.Code)
+	}
+	if _, total := mb.ListDeadLetters(DeadLetterFilter{}); total != 3 {
+		t.Fatalf("expected 3 dead letters kept, got %d", total)
+	}
+	rr = doRequest(t, r, "DELETE", "/dead", map[string]any{"all": true})
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"purged":3`) {
+		t.Errorf("expected all 3 purged, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func postBatch(t *testing.T, r chi.Router, body map[string]any) BatchResponse {
+	t.Helper()
+	rr := doRequest(t, r, "POST", "/jobs/batch", body)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp BatchResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestBatchBestEffort(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	unique := map[string]any{"keys": []string{"type", "args"}}
+	resp := postBatch(t, r, map[string]any{
+		"jobs": []any{
+			map[string]any{"type": "email.send", "args": []any{"a"}, "options": map[string]any{"unique": unique}},
+			map[string]any{"args": []any{}},
+			map[string]any{"type": "email.send", "args": []any{"a"}, "options": map[string]any{"unique": unique}},
+		},
+	})
+
+	if resp.Total != 3 || resp.Succeeded != 1 || resp.Failed != 1 {
+		t.Fatalf("unexpected summary: %+v", resp)
+	}
+	statuses := []string{BatchCreated, BatchFailed, BatchDuplicate}
+	for i, want := range statuses {
+		if resp.Items[i].Index != i || resp.Items[i].Status != want {
+			t.Errorf("item %d: expected %s, got %+v", i, want, resp.Items[i])
+		}
+	}
+	if resp.Items[2].JobID != resp.Items[0].JobID {
+		t.Errorf("expected duplicate to reference %s, got %s", resp.Items[0].JobID, resp.Items[2].JobID)
+	}
+	if len(mb.ListJobs()) != 1 {
+		t.Errorf("expected 1 job stored, got %d", len(mb.ListJobs()))
+	}
+}
+
+func TestBatchAtomicRollsBack(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	resp := postBatch(t, r, map[string]any{
+		"atomic": true,
+		"jobs": []any{
+			map[string]any{"type": "email.send", "args": []any{}},
+			map[string]any{"args": []any{}},
+		},
+	})
+
+	if resp.Failed != 2 || resp.Succeeded != 0 {
+		t.Fatalf("expected whole batch to fail, got %+v", resp)
+	}
+	if resp.Items[0].Error == "" || resp.Items[0].JobID != "" {
+		t.Errorf("expected rolled back item without job id, got %+v", resp.Items[0])
+	}
+	if len(mb.ListJobs()) != 0 {
+		t.Errorf("expected no jobs stored, got %d", len(mb.ListJobs()))
+	}
+
+	resp = postBatch(t, r, map[string]any{
+		"atomic": true,
+		"jobs": []any{
+			map[string]any{"type": "email.send", "args": []any{}},
+			map[string]any{"type": "report.build", "args": []any{}},
+		},
+	})
+	if resp.Succeeded != 2 {
+		t.Errorf("expected 2 created, got %+v", resp)
+	}
+}
+
+func TestBatchAtomicRollsBackInsertFailures(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+	mb.SetQueueConfig("small", QueueConfig{MaxSize: 2})
+
+	small := map[string]any{"queue": "small"}
+	resp := postBatch(t, r, map[string]any{
+		"atomic": true,
+		"jobs": []any{
+			map[string]any{"type": "a", "args": []any{}, "options": small},
+			map[string]any{"type": "b", "args": []any{}, "options": small},
+			map[string]any{"type": "c", "args": []any{}, "options": small},
+		},
+	})
+	if resp.Failed != 3 || !strings.Contains(resp.Items[2].Error, "full") {
+		t.Errorf("expected the batch to be rolled back for the full queue, got %+v", resp)
+	}
+
+	unique := map[string]any{"unique": map[string]any{"keys": []string{"type"}}}
+	resp = postBatch(t, r, map[string]any{
+		"atomic": true,
+		"jobs": []any{
+			map[string]any{"type": "a", "args": []any{}},
+			map[string]any{"type": "u", "args": []any{}, "options": unique},
+			map[string]any{"type": "u", "args": []any{}, "options": unique},
+		},
+	})
+	if resp.Failed != 3 || resp.Items[2].Status != BatchFailed {
+		t.Errorf("expected the batch to be rolled back for the unique conflict, got %+v", resp)
+	}
+	if len(mb.ListJobs()) != 0 {
+		t.Errorf("expected no jobs stored, got %d", len(mb.ListJobs()))
+	}
+}
+
+func TestBatchDropOldest(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+	mb.SetQueueConfig("small", QueueConfig{MaxSize: 2, OverflowPolicy: OverflowDropOldest})
+
+	small := map[string]any{"queue": "small"}
+	older, _, err := mb.Enqueue(&EnqueueRequest{Type: "old", Args: json.RawMessage(`[]`), Options: &EnqueueOptions{Queue: "small"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := []any{
+		map[string]any{"type": "a", "args": []any{}, "options": small},
+		map[string]any{"type": "b", "args": []any{}, "options": small},
+		map[string]any{"type": "c", "args": []any{}, "options": small},
+	}
+
+	resp := postBatch(t, r, map[string]any{"atomic": true, "jobs": batch})
+	if resp.Failed != 3 || !strings.Contains(resp.Items[2].Error, "full") {
+		t.Errorf("expected an atomic batch overflowing the queue to be rolled back, got %+v", resp)
+	}
+
+	resp = postBatch(t, r, map[string]any{"jobs": batch})
+	if resp.Succeeded != 2 || resp.Failed != 1 || resp.Items[0].Status != BatchFailed {
+		t.Errorf("expected the evicted first job to be reported as failed, got %+v", resp)
+	}
+	if job, _ := mb.GetJob(older.ID); job.State != StateDiscarded {
+		t.Errorf("expected the older job to be dropped, got %s", job.State)
+	}
+}
+
+func TestBatchTooLarge(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	jobs := make([]any, MaxBatchSize+1)
+	for i := range jobs {
+		jobs[i] = map[string]any{"type": "email.send", "args": []any{}}
+	}
+	rr := doRequest(t, r, "POST", "/jobs/batch", map[string]any{"jobs": jobs})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rr.Code)
+	}
+}
+
+func submitWorkflow(t *testing.T, r chi.Router, body map[string]any) *Workflow {
+	t.Helper()
+	rr := doRequest(t, r, "POST", "/workflows", body)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Workflow Workflow `json:"workflow"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return &resp.Workflow
+}
+
+func TestWorkflowChainRunsStepsInOrder(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	wf := submitWorkflow(t, r, map[string]any{
+		"type": "chain",
+		"steps": []any{
+			map[string]any{"type": "step.1", "args": []any{}},
+			map[string]any{"type": "step.2", "args": []any{}},
+		},
+	})
+	if wf.Steps[0].State != StateAvailable || wf.Steps[1].State != stepPending {
+		t.Fatalf("expected only the first step enqueued, got %s/%s", wf.Steps[0].State, wf.Steps[1].State)
+	}
+
+	first := fetchOne(t, r)
+	if first.Type != "step.1" || first.WorkflowID != wf.ID {
+		t.Fatalf("expected step.1 of %s, got %s of %s", wf.ID, first.Type, first.WorkflowID)
+	}
+	doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": first.ID})
+
+	second := fetchOne(t, r)
+	if second.Type != "step.2" || second.ParentID != first.ID {
+		t.Fatalf("expected step.2 with parent %s, got %s with parent %s", first.ID, second.Type, second.ParentID)
+	}
+	doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": second.ID})
+
+	got, _ := mb.GetWorkflow(wf.ID)
+	if got.State != WorkflowCompleted {
+		t.Errorf("expected workflow completed, got %s", got.State)
+	}
+}
+
+func TestWorkflowChainStopsOnFailure(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	wf := submitWorkflow(t, r, map[string]any{
+		"type": "chain",
+		"steps": []any{
+			map[string]any{"type": "step.1", "args": []any{}, "options": map[string]any{"retry": map[string]any{"max_attempts": 1}}},
+			map[string]any{"type": "step.2", "args": []any{}},
+		},
+	})
+
+	job := fetchOne(t, r)
+	doRequest(t, r, "POST", "/workers/nack", map[string]any{"job_id": job.ID})
+
+	got, _ := mb.GetWorkflow(wf.ID)
+	if got.State != WorkflowFailed {
+		t.Errorf("expected workflow failed, got %s", got.State)
+	}
+	if got.Steps[1].State != stepPending {
+		t.Errorf("expected second step never enqueued, got %s", got.Steps[1].State)
+	}
+}
+
+func TestWorkflowGroupCallbacks(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	wf := submitWorkflow(t, r, map[string]any{
+		"type": "group",
+		"steps": []any{
+			map[string]any{"type": "part", "args": []any{1}},
+			map[string]any{"type": "part", "args": []any{2}, "options": map[string]any{"retry": map[string]any{"max_attempts": 1}}},
+		},
+		"callbacks": map[string]any{
+			"on_complete": map[string]any{"type": "group.done", "args": []any{}, "options": map[string]any{"queue": "callbacks"}},
+			"on_failure":  map[string]any{"type": "group.failed", "args": []any{}, "options": map[string]any{"queue": "callbacks"}},
+		},
+	})
+
+	rr := doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}, "count": 2})
+	var resp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Jobs) != 2 {
+		t.Fatalf("expected both group steps available, got %d", len(resp.Jobs))
+	}
+	for _, job := range resp.Jobs {
+		if string(job.Args) == "[1]" {
+			doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": job.ID})
+		} else {
+			doRequest(t, r, "POST", "/workers/nack", map[string]any{"job_id": job.ID})
+		}
+	}
+
+	got, _ := mb.GetWorkflow(wf.ID)
+	if got.State != WorkflowFailed {
+		t.Errorf("expected workflow failed, got %s", got.State)
+	}
+
+	rr = doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"callbacks"}, "count": 10})
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	types := map[string]bool{}
+	for _, job := range resp.Jobs {
+		types[job.Type] = true
+	}
+	if len(resp.Jobs) != 2 || !types["group.done"] || !types["group.failed"] {
+		t.Errorf("expected both callbacks enqueued, got %v", types)
+	}
+}
+
+func TestWorkflowProgressEvents(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	b := sse.NewBroadcaster()
+	mb.SetBroadcaster(b)
+	sub, unsub := b.Subscribe(sse.SubscribeFilter{})
+	defer unsub()
+	r := mb.Router()
+
+	wf := submitWorkflow(t, r, map[string]any{
+		"type":  "group",
+		"steps": []any{map[string]any{"type": "part", "args": []any{}}},
+	})
+	job := fetchOne(t, r)
+	doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": job.ID})
+
+	var last sse.Event
+	for last.Type != sse.EventWorkflowCompleted {
+		select {
+		case last = <-sub.Ch:
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", sse.EventWorkflowCompleted)
+		}
+	}
+	data := last.Data.(map[string]any)
+	if data["workflow_id"] != wf.ID || data["completed"] != 1 {
+		t.Errorf("unexpected completion event: %v", data)
+	}
+}
+
+func TestWorkflowRejectsInvalidStep(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	rr := doRequest(t, r, "POST", "/workflows", map[string]any{
+		"type":  "chain",
+		"steps": []any{map[string]any{"type": "ok", "args": []any{}}, map[string]any{"args": []any{}}},
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+	var resp struct {
+		Error struct {
+			Message string       `json:"message"`
+			Details []FieldError `json:"details"`
+		} `json:"error"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if !strings.Contains(resp.Error.Message, "steps[1]") {
+		t.Errorf("expected error to name steps[1], got %s", rr.Body.String())
+	}
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Path != "steps[1].type" {
+		t.Errorf("expected a detail for steps[1].type, got %+v", resp.Error.Details)
+	}
+	if len(mb.ListJobs()) != 0 {
+		t.Errorf("expected no jobs enqueued, got %d", len(mb.ListJobs()))
+	}
+}
+
+func TestWorkflowStepIDs(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	existing := createJob(t, r, "other")
+	for name, steps := range map[string][]any{
+		"repeated": {
+			map[string]any{"id": "0190c5a0-0000-7000-8000-000000000001", "type": "a", "args": []any{}},
+			map[string]any{"id": "0190c5a0-0000-7000-8000-000000000001", "type": "b", "args": []any{}},
+		},
+		"taken": {
+			map[string]any{"type": "a", "args": []any{}},
+			map[string]any{"id": existing.ID, "type": "b", "args": []any{}},
+		},
+	} {
+		rr := doRequest(t, r, "POST", "/workflows", map[string]any{"type": "chain", "steps": steps})
+		if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "steps[1]") {
+			t.Errorf("%s: expected 409 naming steps[1], got %d: %s", name, rr.Code, rr.Body.String())
+		}
+	}
+	if len(mb.ListWorkflows()) != 0 || len(mb.ListJobs()) != 1 {
+		t.Fatalf("expected no workflow stored, got %d workflows and %d jobs", len(mb.ListWorkflows()), len(mb.ListJobs()))
+	}
+
+	// A step whose ID is taken before it is enqueued fails the workflow.
+	wf := submitWorkflow(t, r, map[string]any{
+		"type": "chain",
+		"steps": []any{
+			map[string]any{"type": "a", "args": []any{}},
+			map[string]any{"type": "b", "args": []any{}},
+		},
+	})
+	fetchN(t, r, "default", 10)
+	doRequest(t, r, "POST", "/jobs", map[string]any{"id": wf.Steps[1].JobID, "type": "other", "args": []any{}})
+	doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": wf.Steps[0].JobID})
+
+	got, _ := mb.GetWorkflow(wf.ID)
+	if got.State != WorkflowFailed || got.Steps[1].State != StateDiscarded || got.Steps[1].Error == "" {
+		t.Errorf("expected the workflow failed on its taken step, got %s with step %+v", got.State, got.Steps[1])
+	}
+}
+
+func TestWorkflowStepDroppedOnEnqueue(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	policy := map[string]any{"key": "sms", "concurrency": 1, "on_limit": "drop"}
+	doRequest(t, r, "POST", "/jobs", map[string]any{"type": "hold", "args": []any{}, "options": map[string]any{"rate_limit": policy}})
+	fetchOne(t, r)
+
+	wf := submitWorkflow(t, r, map[string]any{
+		"type": "chain",
+		"steps": []any{
+			map[string]any{"type": "a", "args": []any{}},
+			map[string]any{"type": "b", "args": []any{}, "options": map[string]any{"rate_limit": policy}},
+		},
+	})
+	job := fetchOne(t, r)
+	doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": job.ID})
+
+	got, _ := mb.GetWorkflow(wf.ID)
+	if got.State != WorkflowFailed || got.Steps[1].State != StateDiscarded {
+		t.Errorf("expected the workflow failed on its dropped step, got %s with step %+v", got.State, got.Steps[1])
+	}
+}
+
+func TestCreateJobDuplicateID(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	job := createJob(t, r, "email.send")
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{"id": job.ID, "type": "other", "args": []any{}})
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got, _ := mb.GetJob(job.ID); got.Type != "email.send" {
+		t.Errorf("expected the original job to be kept, got %+v", got)
+	}
+	if fetched := fetchOne(t, r); fetched.ID != job.ID {
+		t.Errorf("expected the original job to stay fetchable, got %+v", fetched)
+	}
+}
+
+func TestQueueFIFOWithinPriority(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	for i := 0; i < 20; i++ {
+		doRequest(t, r, "POST", "/jobs", map[string]any{
+			"type":    "ordered",
+			"args":    []any{i},
+			"options": map[string]any{"priority": i % 2},
+		})
+	}
+
+	rr := doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}, "count": 20})
+	var resp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+
+	var got []string
+	for _, job := range resp.Jobs {
+		got = append(got, string(job.Args))
+	}
+	want := []string{"[1]", "[3]", "[5]", "[7]", "[9]", "[11]", "[13]", "[15]", "[17]", "[19]",
+		"[0]", "[2]", "[4]", "[6]", "[8]", "[10]", "[12]", "[14]", "[16]", "[18]"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCancelRemovesFromQueue(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		ids = append(ids, createJob(t, r, "email.send").ID)
+	}
+	doRequest(t, r, "DELETE", "/jobs/"+ids[2], nil)
+
+	rr := doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}, "count": 10})
+	var resp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Jobs) != 4 {
+		t.Fatalf("expected 4 jobs, got %d", len(resp.Jobs))
+	}
+	for _, job := range resp.Jobs {
+		if job.ID == ids[2] {
+			t.Errorf("cancelled job %s was fetched", job.ID)
+		}
+	}
+}
+
+// benchmarkQueueDepth is how many jobs are already queued when each
+// benchmark starts, so that per-operation cost reflects a loaded queue.
+const benchmarkQueueDepth = 50000
+
+func preloadJobs(b *testing.B, mb *MemoryBackend, n int) []string {
+	b.Helper()
+	ids := make([]string, n)
+	for i := 0; i < n; i++ {
+		priority := i % 10
+		job, _, err := mb.Enqueue(&EnqueueRequest{
+			Type:    "bench",
+			Args:    json.RawMessage(`[]`),
+			Options: &EnqueueOptions{Priority: &priority},
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+		ids[i] = job.ID
+	}
+	return ids
+}
+
+func BenchmarkEnqueue(b *testing.B) {
+	mb := newTestBackend()
+	defer mb.Close()
+	preloadJobs(b, mb, benchmarkQueueDepth)
+
+	b.ResetTimer()
+	preloadJobs(b, mb, b.N)
+}
+
+func BenchmarkFetch(b *testing.B) {
+	mb := newTestBackend()
+	defer mb.Close()
+	r := mb.Router()
+	preloadJobs(b, mb, benchmarkQueueDepth+b.N)
+	body := []byte(`{"queues":["default"]}`)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("POST", "/workers/fetch", bytes.NewReader(body)))
+	}
+}
+
+func BenchmarkCancel(b *testing.B) {
+	mb := newTestBackend()
+	defer mb.Close()
+	r := mb.Router()
+	ids := preloadJobs(b, mb, benchmarkQueueDepth+b.N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Cancel from the middle of the queue, where a linear scan is slowest.
+		id := ids[benchmarkQueueDepth/2+i]
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("DELETE", "/jobs/"+id, nil))
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	nacked := createJob(t, r, "nacked")
+	doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "urgent", "args": []any{}, "options": map[string]any{"priority": 5},
+	})
+	createJob(t, r, "queued")
+	fetchOne(t, r) // urgent
+	if job := fetchOne(t, r); job.ID != nacked.ID {
+		t.Fatalf("expected %s, got %s", nacked.ID, job.ID)
+	}
+	doRequest(t, r, "POST", "/workers/nack", map[string]any{"job_id": nacked.ID})
+	mb.PauseQueue("email")
+
+	var buf bytes.Buffer
+	if err := mb.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newTestBackend()
+	restored.Close()
+	if err := restored.RestoreSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	job, ok := restored.GetJob(nacked.ID)
+	if !ok || job.State != StateRetryable {
+		t.Fatalf("expected retryable job %s after restore, got %+v", nacked.ID, job)
+	}
+	if stats, _ := restored.Stats(context.Background()); len(stats.PausedQueues) != 1 || stats.PausedQueues[0] != "email" {
+		t.Errorf("expected email paused after restore, got %v", stats.PausedQueues)
+	}
+
+	rr := restored.Router()
+	if next := fetchOne(t, rr); next.Type != "queued" {
+		t.Errorf("expected queued job to survive restore, got %s", next.Type)
+	}
+
+	// The retry timer survives too.
+	restored.tick(time.Now().Add(time.Hour))
+	if next := fetchOne(t, rr); next.ID != nacked.ID {
+		t.Errorf("expected retried job after its timer, got %s", next.ID)
+	}
+}
+
+func TestSnapshotStore(t *testing.T) {
+	mb := newTestBackend()
+	defer mb.Close()
+	r := mb.Router()
+	store := NewSnapshotStore(t.TempDir(), mb)
+
+	job := createJob(t, r, "email.send")
+	if _, err := store.Save("demo"); err != nil {
+		t.Fatal(err)
+	}
+	doRequest(t, r, "DELETE", "/jobs/"+job.ID, nil)
+
+	infos, err := store.List()
+	if err != nil || len(infos) != 1 || infos[0].Name != "demo" {
+		t.Fatalf("expected one snapshot named demo, got %v (%v)", infos, err)
+	}
+
+	if err := store.Load("demo"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := mb.GetJob(job.ID); got.State != StateAvailable {
+		t.Errorf("expected job available after load, got %s", got.State)
+	}
+
+	if err := store.Load("missing"); err != ErrSnapshotNotFound {
+		t.Errorf("expected ErrSnapshotNotFound, got %v", err)
+	}
+	if _, err := store.Save("../escape"); err == nil {
+		t.Error("expected invalid name to be rejected")
+	}
+}
+
+func fetchN(t *testing.T, r chi.Router, queue string, count int) []MemoryJob {
+	t.Helper()
+	rr := doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{queue}, "count": count})
+	var resp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	return resp.Jobs
+}
+
+func TestQueueConcurrencyLimit(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	rr := doRequest(t, r, "PUT", "/queues/default/config", map[string]any{"concurrency": 2})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	for i := 0; i < 3; i++ {
+		createJob(t, r, "email.send")
+	}
+
+	jobs := fetchN(t, r, "default", 3)
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs under concurrency 2, got %d", len(jobs))
+	}
+	if more := fetchN(t, r, "default", 1); len(more) != 0 {
+		t.Fatalf("expected the third job to wait, got %d", len(more))
+	}
+
+	doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": jobs[0].ID})
+	if more := fetchN(t, r, "default", 1); len(more) != 1 {
+		t.Errorf("expected the waiting job once a slot freed, got %d", len(more))
+	}
+
+	stats, _ := mb.Stats(context.Background())
+	if stats.RateLimitHits["queue:default"] != 1 {
+		t.Errorf("expected 1 hit on queue:default, got %v", stats.RateLimitHits)
+	}
+}
+
+func TestRateLimitKeySpansQueues(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	policy := map[string]any{"key": "stripe", "rate": map[string]any{"limit": 1, "period": "PT1M"}}
+	for _, q := range []string{"a", "b"} {
+		doRequest(t, r, "POST", "/jobs", map[string]any{
+			"type": "charge", "args": []any{}, "options": map[string]any{"queue": q, "rate_limit": policy},
+		})
+	}
+	doRequest(t, r, "POST", "/jobs", map[string]any{"type": "other", "args": []any{}, "options": map[string]any{"queue": "b"}})
+
+	if jobs := fetchN(t, r, "a", 1); len(jobs) != 1 {
+		t.Fatalf("expected first charge, got %d", len(jobs))
+	}
+	jobs := fetchN(t, r, "b", 2)
+	if len(jobs) != 1 || jobs[0].Type != "other" {
+		t.Fatalf("expected only the unlimited job from b, got %+v", jobs)
+	}
+}
+
+func TestRateLimitReschedule(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	policy := map[string]any{"key": "webhook", "throttle": map[string]any{"limit": 1, "period": "PT10S"}, "on_limit": "reschedule"}
+	for i := 0; i < 2; i++ {
+		doRequest(t, r, "POST", "/jobs", map[string]any{"type": "deliver", "args": []any{i}, "options": map[string]any{"rate_limit": policy}})
+	}
+
+	jobs := fetchN(t, r, "default", 2)
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job through the throttle, got %d", len(jobs))
+	}
+	var rescheduled *MemoryJob
+	for _, j := range mb.ListJobs() {
+		if j.State == StateScheduled {
+			rescheduled = j
+		}
+	}
+	if rescheduled == nil {
+		t.Fatal("expected the throttled job to be rescheduled")
+	}
+
+	mb.tick(time.Now().Add(11 * time.Second))
+	if got, _ := mb.GetJob(rescheduled.ID); got.State != StateAvailable {
+		t.Errorf("expected rescheduled job available after the throttle period, got %s", got.State)
+	}
+}
+
+func TestRateLimitDrop(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	b := sse.NewBroadcaster()
+	mb.SetBroadcaster(b)
+	sub, unsub := b.Subscribe(sse.SubscribeFilter{})
+	defer unsub()
+	r := mb.Router()
+
+	policy := map[string]any{"key": "email", "concurrency": 1, "on_limit": "drop"}
+	for i := 0; i < 2; i++ {
+		doRequest(t, r, "POST", "/jobs", map[string]any{"type": "send", "args": []any{i}, "options": map[string]any{"rate_limit": policy}})
+	}
+
+	if jobs := fetchN(t, r, "default", 2); len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+	discarded := 0
+	for _, j := range mb.ListJobs() {
+		if j.State == StateDiscarded {
+			discarded++
+		}
+	}
+	if discarded != 1 {
+		t.Errorf("expected 1 dropped job, got %d", discarded)
+	}
+
+	select {
+	case ev := <-sub.Ch:
+		if ev.Type != sse.EventJobRateLimited {
+			t.Errorf("expected %s, got %s", sse.EventJobRateLimited, ev.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for rate limit event")
+	}
+}
+
+func TestRateLimitHitCountedOncePerJob(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	b := sse.NewBroadcaster()
+	mb.SetBroadcaster(b)
+	sub, unsub := b.Subscribe(sse.SubscribeFilter{})
+	defer unsub()
+	r := mb.Router()
+
+	policy := map[string]any{"key": "pdf", "concurrency": 1}
+	for i := 0; i < 2; i++ {
+		doRequest(t, r, "POST", "/jobs", map[string]any{"type": "render", "args": []any{i}, "options": map[string]any{"rate_limit": policy}})
+	}
+	if jobs := fetchN(t, r, "default", 1); len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+	for i := 0; i < 3; i++ {
+		if more := fetchN(t, r, "default", 1); len(more) != 0 {
+			t.Fatalf("expected the second job to wait, got %d", len(more))
+		}
+	}
+
+	stats, _ := mb.Stats(context.Background())
+	if stats.RateLimitHits["key:pdf"] != 1 {
+		t.Errorf("expected 1 hit on key:pdf, got %v", stats.RateLimitHits)
+	}
+	limited := 0
+	for len(sub.Ch) > 0 {
+		if ev := <-sub.Ch; ev.Type == sse.EventJobRateLimited {
+			limited++
+		}
+	}
+	if limited != 1 {
+		t.Errorf("expected 1 %s event, got %d", sse.EventJobRateLimited, limited)
+	}
+}
+
+func TestRateLimitAppliesOnEnqueue(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	policy := map[string]any{"key": "sms", "concurrency": 1, "on_limit": "drop"}
+	doRequest(t, r, "POST", "/jobs", map[string]any{"type": "send", "args": []any{0}, "options": map[string]any{"rate_limit": policy}})
+	if jobs := fetchN(t, r, "default", 1); len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{"type": "send", "args": []any{1}, "options": map[string]any{"rate_limit": policy}})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.NewDecoder(rr.Body).Decode(&created)
+	if got, _ := mb.GetJob(created.Job.ID); got.State != StateDiscarded {
+		t.Errorf("expected the job over the limit dropped on enqueue, got %s", got.State)
+	}
+}
+
+func TestRateLimitValidation(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "send", "args": []any{}, "options": map[string]any{"rate_limit": map[string]any{"concurrency": 1}},
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 without key, got %d", rr.Code)
+	}
+
+	rr = doRequest(t, r, "PUT", "/queues/default/config", map[string]any{
+		"rate_limit": map[string]any{"rate": map[string]any{"limit": 1, "period": "soon"}},
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for bad period, got %d", rr.Code)
+	}
+}
+
+func TestQueueMaxSizeReject(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	doRequest(t, r, "PUT", "/queues/default/config", map[string]any{"max_size": 2})
+	createJob(t, r, "a")
+	createJob(t, r, "b")
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{"type": "c", "args": []any{}})
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), "queue_full") {
+		t.Errorf("expected queue_full error, got %s", rr.Body.String())
+	}
+
+	fetchOne(t, r)
+	createJob(t, r, "c")
+}
+
+func TestQueueMaxSizeDropOldest(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	doRequest(t, r, "PUT", "/queues/default/config", map[string]any{"max_size": 2, "overflow_policy": "drop_oldest"})
+	first := createJob(t, r, "a")
+	createJob(t, r, "b")
+	createJob(t, r, "c")
+
+	dropped, _ := mb.GetJob(first.ID)
+	if dropped.State != StateDiscarded {
+		t.Errorf("expected oldest job to be discarded, got %s", dropped.State)
+	}
+	jobs := fetchN(t, r, "default", 10)
+	if len(jobs) != 2 || jobs[0].Type != "b" || jobs[1].Type != "c" {
+		t.Errorf("expected jobs b and c to remain, got %+v", jobs)
+	}
+}
+
+func TestQueueMaxSizeDropOldestKeepsJobsOnRejectedEnqueue(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	doRequest(t, r, "PUT", "/queues/default/config", map[string]any{"max_size": 2, "overflow_policy": "drop_oldest"})
+	unique := map[string]any{"keys": []string{"type"}, "on_conflict": "reject"}
+	first := createJob(t, r, "a")
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type":    "b",
+		"args":    []any{},
+		"options": map[string]any{"unique": unique},
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = doRequest(t, r, "POST", "/jobs", map[string]any{"id": first.ID, "type": "c", "args": []any{}})
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a duplicate ID, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type":    "b",
+		"args":    []any{},
+		"options": map[string]any{"unique": unique},
+	})
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a unique conflict, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type":    "d",
+		"args":    []any{},
+		"options": map[string]any{"scheduled_at": "2030-01-01T00:00:00Z"},
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for a scheduled job, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if job, _ := mb.GetJob(first.ID); job.State != StateAvailable {
+		t.Errorf("expected oldest job to stay available, got %s", job.State)
+	}
+	if jobs := fetchN(t, r, "default", 10); len(jobs) != 2 {
+		t.Errorf("expected both waiting jobs to remain, got %d", len(jobs))
+	}
+}
+
+func TestQueueMaxSizeBlock(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	doRequest(t, r, "PUT", "/queues/default/config", map[string]any{"max_size": 1, "overflow_policy": "block"})
+	createJob(t, r, "a")
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := mb.Enqueue(&EnqueueRequest{Type: "b", Args: json.RawMessage(`[]`)})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected enqueue to block, returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	fetchOne(t, r)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected blocked enqueue to succeed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("enqueue still blocked after room was made")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := mb.EnqueueContext(ctx, &EnqueueRequest{Type: "c", Args: json.RawMessage(`[]`)}); !isQueueFull(err) {
+		t.Errorf("expected queue_full once the context is done, got %v", err)
+	}
+}
+
+func TestQueueAllowedJobTypes(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	doRequest(t, r, "PUT", "/queues/default/config", map[string]any{"allowed_job_types": []string{"email.*", "report"}})
+	createJob(t, r, "email.send")
+	createJob(t, r, "report")
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{"type": "sms.send", "args": []any{}})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for disallowed type, got %d", rr.Code)
+	}
+}
+
+func TestQueueDefaultTimeoutAndRetry(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	doRequest(t, r, "PUT", "/queues/default/config", map[string]any{
+		"default_timeout": 30,
+		"default_retry":   map[string]any{"max_attempts": 7},
+	})
+	job := createJob(t, r, "a")
+	if job.Timeout == nil || job.Timeout.Execution != 30 {
+		t.Errorf("expected default execution timeout of 30s, got %+v", job.Timeout)
+	}
+	if job.MaxAttempts != 7 {
+		t.Errorf("expected default max_attempts 7, got %d", job.MaxAttempts)
+	}
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "b", "args": []any{}, "options": map[string]any{"retry": map[string]any{"max_attempts": 2}},
+	})
+	var resp struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Job.MaxAttempts != 2 {
+		t.Errorf("expected job retry policy to override the default, got %d", resp.Job.MaxAttempts)
+	}
+}
+
+func TestQueueRetention(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	doRequest(t, r, "PUT", "/queues/default/config", map[string]any{"retention": map[string]any{"completed": "PT1M"}})
+	done := createJob(t, r, "a")
+	fetchOne(t, r)
+	doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": done.ID})
+	waiting := createJob(t, r, "b")
+
+	mb.tick(time.Now().Add(30 * time.Second))
+	if _, ok := mb.GetJob(done.ID); !ok {
+		t.Fatal("expected completed job to be kept within its retention window")
+	}
+
+	mb.tick(time.Now().Add(2 * time.Minute))
+	if _, ok := mb.GetJob(done.ID); ok {
+		t.Error("expected completed job to be removed after its retention window")
+	}
+	if _, ok := mb.GetJob(waiting.ID); !ok {
+		t.Error("expected unfinished job to be kept")
+	}
+}
+
+func TestQueueRetentionSkipsRetriedJobs(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	doRequest(t, r, "PUT", "/queues/default/config", map[string]any{"retention": map[string]any{"cancelled": "PT1M"}})
+	job := createJob(t, r, "a")
+	if _, err := mb.CancelJob(context.Background(), job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mb.RetryJob(context.Background(), job.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	mb.tick(time.Now().Add(2 * time.Minute))
+	if got, ok := mb.GetJob(job.ID); !ok || got.State != StateAvailable {
+		t.Errorf("expected the retried job to be kept, got %+v", got)
+	}
+	if len(mb.finished) != 0 {
+		t.Errorf("expected the retried job dropped from the finished index, got %v", mb.finished)
+	}
+}
+
+func TestQueueConfigValidation(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	for _, cfg := range []map[string]any{
+		{"max_size": -1},
+		{"max_size": 5, "overflow_policy": "spill"},
+		{"retention": map[string]any{"completed": "forever"}},
+		{"default_retry": map[string]any{"initial_interval": "soon"}},
+	} {
+		if rr := doRequest(t, r, "PUT", "/queues/default/config", cfg); rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422 for %v, got %d", cfg, rr.Code)
+		}
+	}
+}
+
+func TestReportProgress(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	b := sse.NewBroadcaster()
+	mb.SetBroadcaster(b)
+	sub, unsub := b.Subscribe(sse.SubscribeFilter{})
+	defer unsub()
+	var reported []ProgressUpdate
+	mb.SetProgressCallback(func(jobID string, p ProgressUpdate) { reported = append(reported, p) })
+	r := mb.Router()
+
+	job := createJob(t, r, "import")
+	rr := doRequest(t, r, "POST", "/jobs/"+job.ID+"/progress", map[string]any{"value": 0.5})
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected 409 for job that is not active, got %d", rr.Code)
+	}
+
+	fetchOne(t, r)
+	rr = doRequest(t, r, "POST", "/jobs/"+job.ID+"/progress", map[string]any{
+		"value": 0.25, "message": "page 1", "checkpoint": map[string]any{"page": 1},
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	doRequest(t, r, "POST", "/jobs/"+job.ID+"/progress", map[string]any{"value": 0.5, "message": "page 2"})
+
+	got, _ := mb.GetJob(job.ID)
+	if got.Progress == nil || got.Progress.Value != 0.5 || got.Progress.Message != "page 2" {
+		t.Fatalf("expected latest progress to be stored, got %+v", got.Progress)
+	}
+	if string(got.Progress.Checkpoint) != `{"page":1}` {
+		t.Errorf("expected checkpoint to be kept, got %s", got.Progress.Checkpoint)
+	}
+	if len(reported) != 2 {
+		t.Errorf("expected 2 progress callbacks, got %d", len(reported))
+	}
+
+	select {
+	case ev := <-sub.Ch:
+		if ev.Type != sse.EventJobProgress {
+			t.Errorf("expected %s, got %s", sse.EventJobProgress, ev.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for progress event")
+	}
+}
+
+func TestReportProgressValidation(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	job := createJob(t, r, "import")
+	fetchOne(t, r)
+	for _, body := range []map[string]any{
+		{"value": 1.5},
+		{"value": 0.5, "checkpoint": []any{1}},
+	} {
+		if rr := doRequest(t, r, "POST", "/jobs/"+job.ID+"/progress", body); rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422 for %v, got %d", body, rr.Code)
+		}
+	}
+	if rr := doRequest(t, r, "POST", "/jobs/missing/progress", map[string]any{"value": 0}); rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown job, got %d", rr.Code)
+	}
+}
+
+func TestEnqueueTTLExpiresUnfetchedJob(t *testing.T) {
+	var reasons []string
+	mb := NewMemoryBackend(func(job *MemoryJob, from, to, reason string) {
+		if to == StateDiscarded {
+			reasons = append(reasons, reason)
+		}
+	})
+	mb.Close()
+	b := sse.NewBroadcaster()
+	mb.SetBroadcaster(b)
+	sub, unsub := b.Subscribe(sse.SubscribeFilter{})
+	defer unsub()
+	r := mb.Router()
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "report", "args": []any{}, "options": map[string]any{"timeout": map[string]any{"enqueue_ttl": 60}},
+	})
+	var resp struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Job.ExpiresAt == "" {
+		t.Fatal("expected expires_at to be set from enqueue_ttl")
+	}
+
+	mb.tick(time.Now().Add(30 * time.Second))
+	if job, _ := mb.GetJob(resp.Job.ID); job.State != StateAvailable {
+		t.Fatalf("expected job to wait until it expires, got %s", job.State)
+	}
+
+	mb.tick(time.Now().Add(2 * time.Minute))
+	job, _ := mb.GetJob(resp.Job.ID)
+	if job.State != StateDiscarded || !strings.Contains(string(job.Error), ReasonExpired) {
+		t.Fatalf("expected job to be discarded as expired, got %s %s", job.State, job.Error)
+	}
+	if len(reasons) != 1 || reasons[0] != ReasonExpired {
+		t.Errorf("expected state change reason %q, got %v", ReasonExpired, reasons)
+	}
+
+	select {
+	case ev := <-sub.Ch:
+		if ev.Type != sse.EventJobExpired {
+			t.Errorf("expected %s, got %s", sse.EventJobExpired, ev.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for expired event")
+	}
+}
+
+func TestFetchSkipsExpiredJob(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "stale", "args": []any{}, "options": map[string]any{"expires_at": time.Now().Add(-time.Second).Format(time.RFC3339)},
+	})
+	var resp struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	fresh := createJob(t, r, "fresh")
+
+	jobs := fetchN(t, r, "default", 2)
+	if len(jobs) != 1 || jobs[0].ID != fresh.ID {
+		t.Fatalf("expected only the unexpired job, got %+v", jobs)
+	}
+	if job, _ := mb.GetJob(resp.Job.ID); job.State != StateDiscarded {
+		t.Errorf("expected expired job to be discarded, got %s", job.State)
+	}
+}
+
+func TestStartedJobDoesNotExpire(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "report", "args": []any{}, "options": map[string]any{"expires_at": time.Now().Add(time.Minute).Format(time.RFC3339)},
+	})
+	var resp struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	fetchOne(t, r)
+	doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": resp.Job.ID})
+
+	mb.tick(time.Now().Add(2 * time.Minute))
+	if job, _ := mb.GetJob(resp.Job.ID); job.State != StateCompleted {
+		t.Errorf("expected started job to be unaffected by expiry, got %s", job.State)
+	}
+
+	rr = doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "report", "args": []any{}, "options": map[string]any{"expires_at": "tomorrow"},
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for invalid expires_at, got %d", rr.Code)
+	}
+	var errResp struct {
+		Error struct {
+			Details []FieldError `json:"details"`
+		} `json:"error"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &errResp)
+	if len(errResp.Error.Details) != 1 || errResp.Error.Details[0].Path != "options.expires_at" {
+		t.Errorf("expected a detail for options.expires_at, got %+v", errResp.Error.Details)
+	}
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	mb := newTestBackend()
+	mb.Close()
+	r := mb.Router()
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"specversion": SpecVersion,
+		"type":        "email.send",
+		"args":        []any{"user@example.com"},
+		"meta":        map[string]any{"trace_id": "abc"},
+		"schema":      "urn:ojs:schema:email.send:v1",
+		"options": map[string]any{
+			"queue":      "email",
+			"priority":   5,
+			"retry":      map[string]any{"max_attempts": 4, "initial_interval": "PT2S"},
+			"unique":     map[string]any{"keys": []string{"args"}, "on_conflict": "reject"},
+			"timeout":    map[string]any{"execution": 60, "heartbeat": 10},
+			"expires_at": expiresAt.Format(time.RFC3339),
+		},
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &created)
+
+	check := func(where string, job *MemoryJob) {
+		t.Helper()
+		if job.SpecVersion != SpecVersion || job.Schema != "urn:ojs:schema:email.send:v1" {
+			t.Errorf("%s: expected specversion and schema, got %q %q", where, job.SpecVersion, job.Schema)
+		}
+		if job.Queue != "email" || job.Priority != 5 || string(job.Meta) != `{"trace_id":"abc"}` {
+			t.Errorf("%s: expected queue, priority and meta to be kept, got %+v", where, job)
+		}
+		if job.Retry == nil || job.Retry.MaxAttempts != 4 || job.Retry.InitialInterval != "PT2S" {
+			t.Errorf("%s: expected retry policy, got %+v", where, job.Retry)
+		}
+		if job.Unique == nil || job.Unique.OnConflict != OnConflictReject {
+			t.Errorf("%s: expected unique policy, got %+v", where, job.Unique)
+		}
+		if job.Timeout == nil || job.Timeout.Execution != 60 || job.Timeout.Heartbeat != 10 {
+			t.Errorf("%s: expected timeout policy, got %+v", where, job.Timeout)
+		}
+		if got, _ := time.Parse(time.RFC3339, job.ExpiresAt); !got.Equal(expiresAt) {
+			t.Errorf("%s: expected expires_at %s, got %s", where, expiresAt, job.ExpiresAt)
+		}
+	}
+	check("create", &created.Job)
+
+	rr = doRequest(t, r, "GET", "/jobs/"+created.Job.ID, nil)
+	var got struct {
+		Job MemoryJob `json:"job"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &got)
+	check("get", &got.Job)
+
+	jobs := fetchN(t, r, "email", 1)
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+	check("fetch", &jobs[0])
+}
+
+func TestEnvelopeValidationDetails(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+
+	rr := doRequest(t, r, "POST", "/jobs", map[string]any{
+		"specversion": "0.9",
+		"type":        "email.send",
+		"args":        map[string]any{"to": "x"},
+		"options": map[string]any{
+			"retry":   map[string]any{"max_attempts": -1},
+			"timeout": map[string]any{"execution": -1},
+			"colour":  "blue",
+		},
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Error struct {
+			Code    string       `json:"code"`
+			Details []FieldError `json:"details"`
+		} `json:"error"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Error.Code != "validation_error" {
+		t.Errorf("expected validation_error, got %q", resp.Error.Code)
+	}
+	paths := map[string]bool{}
+	for _, d := range resp.Error.Details {
+		paths[d.Path] = true
+	}
+	for _, want := range []string{"specversion", "args", "options.colour", "options.retry.max_attempts", "options.timeout.execution"} {
+		if !paths[want] {
+			t.Errorf("expected an error for %s, got %+v", want, resp.Error.Details)
+		}
+	}
+
+	rr = doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type": "email.send", "args": []any{}, "options": map[string]any{"priority": "high"},
+	})
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusUnprocessableEntity || len(resp.Error.Details) != 1 || resp.Error.Details[0].Path != "options.priority" {
+		t.Errorf("expected 422 naming options.priority, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestArgsValidatorRejectsJob(t *testing.T) {
+	mb := newTestBackend()
+	var gotType, gotSchema string
+	mb.SetArgsValidator(func(jobType, schema string, args json.RawMessage) []FieldError {
+		gotType, gotSchema = jobType, schema
+		if string(args) == `["ok"]` {
+			return nil
+		}
+		return []FieldError{{Path: "0", Message: "must be a valid email"}, {Message: "must have 2 items"}}
+	})
+
+	_, _, err := mb.Enqueue(&EnqueueRequest{Type: "email.send", Schema: "urn:ojs:schema:email.send:v1", Args: json.RawMessage(`[1]`)})
+	reqErr, ok := err.(*RequestError)
+	if !ok || reqErr.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected a 422 validation error, got %v", err)
+	}
+	details := reqErr.Details["details"].([]FieldError)
+	if len(details) != 2 || details[0].Path != "args.0" || details[1].Path != "args" {
+		t.Errorf("expected errors at args.0 and args, got %+v", details)
+	}
+	if gotType != "email.send" || gotSchema != "urn:ojs:schema:email.send:v1" {
+		t.Errorf("expected the job type and schema URI to be passed, got %q %q", gotType, gotSchema)
+	}
+
+	if _, _, err := mb.Enqueue(&EnqueueRequest{Type: "email.send", Args: json.RawMessage(`["ok"]`)}); err != nil {
+		t.Errorf("expected matching args to be accepted, got %v", err)
+	}
+}
+
+// waitForWaiters waits until n fetches are waiting on mb.
+func waitForWaiters(t *testing.T, mb *MemoryBackend, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mb.mu.RLock()
+		got := len(mb.waiters)
+		mb.mu.RUnlock()
+		if got == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d waiting fetches", n)
+}
+
+func TestFetchWaitsForJob(t *testing.T) {
+	mb := newTestBackend()
+	defer mb.Close()
+	r := mb.Router()
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}, "wait_ms": 5000})
+	}()
+	waitForWaiters(t, mb, 1)
+
+	start := time.Now()
+	job, _, _ := mb.Enqueue(&EnqueueRequest{Type: "email.send"})
+	rr := <-done
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the waiting fetch to return promptly, took %s", elapsed)
+	}
+	var resp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Jobs) != 1 || resp.Jobs[0].ID != job.ID || resp.Jobs[0].State != StateActive {
+		t.Errorf("expected the enqueued job to be fetched, got %s", rr.Body.String())
+	}
+}
+
+func TestFetchWaitTimesOut(t *testing.T) {
+	mb := newTestBackend()
+	defer mb.Close()
+
+	start := time.Now()
+	jobs, err := mb.Fetch(context.Background(), FetchRequest{Queues: []string{"default"}, WaitMs: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("expected no jobs, got %d", len(jobs))
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected fetch to wait, returned after %s", elapsed)
+	}
+	waitForWaiters(t, mb, 0)
+
+	rr := doRequest(t, mb.Router(), "POST", "/workers/fetch", map[string]any{"wait_ms": -1})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a negative wait_ms, got %d", rr.Code)
+	}
+}
+
+func TestFetchWaitEndsOnDisconnect(t *testing.T) {
+	mb := newTestBackend()
+	defer mb.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []*MemoryJob)
+	go func() {
+		jobs, _ := mb.Fetch(ctx, FetchRequest{WaitMs: 5000})
+		done <- jobs
+	}()
+	waitForWaiters(t, mb, 1)
+	cancel()
+
+	select {
+	case jobs := <-done:
+		if len(jobs) != 0 {
+			t.Errorf("expected no jobs, got %d", len(jobs))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected fetch to stop waiting when the client went away")
+	}
+	waitForWaiters(t, mb, 0)
+
+	// A job enqueued afterwards stays available for the next worker.
+	job := createJob(t, mb.Router(), "email.send")
+	if got, _ := mb.GetJob(job.ID); got.State != StateAvailable {
+		t.Errorf("expected the job to stay available, got %s", got.State)
+	}
+}
+
+func TestFetchWaitersServedInOrder(t *testing.T) {
+	mb := newTestBackend()
+	defer mb.Close()
+
+	results := make([]chan []*MemoryJob, 3)
+	for i := range results {
+		results[i] = make(chan []*MemoryJob, 1)
+		go func(ch chan []*MemoryJob) {
+			jobs, _ := mb.Fetch(context.Background(), FetchRequest{Queues: []string{"default"}, WaitMs: 5000})
+			ch <- jobs
+		}(results[i])
+		waitForWaiters(t, mb, i+1)
+	}
+
+	for i := range results {
+		job, _, _ := mb.Enqueue(&EnqueueRequest{Type: "email.send"})
+		select {
+		case jobs := <-results[i]:
+			if len(jobs) != 1 || jobs[0].ID != job.ID {
+				t.Errorf("expected waiter %d to get job %s, got %+v", i, job.ID, jobs)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected waiter %d to be served", i)
+		}
+	}
+}
+
+func TestWorkerHoldsLease(t *testing.T) {
+	mb := newTestBackend()
+	r := mb.Router()
+	job := createJob(t, r, "email.send")
+	createJob(t, r, "email.send")
+
+	rr := doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}, "worker_id": "worker-1"})
+	var resp struct {
+		Jobs []MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Jobs) != 1 || resp.Jobs[0].WorkerID != "worker-1" {
+		t.Fatalf("expected the job to be leased to worker-1, got %s", rr.Body.String())
+	}
+	doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}, "worker_id": "worker-2"})
+
+	stats, _ := mb.Stats(context.Background())
+	if stats.WorkerCount != 2 {
+		t.Errorf("expected 2 workers, got %d", stats.WorkerCount)
+	}
+	held := mb.WorkerJobs("worker-1")
+	if len(held) != 1 || held[0].ID != job.ID {
+		t.Errorf("expected worker-1 to hold %s, got %+v", job.ID, held)
+	}
+
+	for _, path := range []string{"/workers/ack", "/workers/nack", "/workers/heartbeat"} {
+		rr := doRequest(t, r, "POST", path, map[string]any{"job_id": job.ID, "worker_id": "worker-2"})
+		if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "lease_mismatch") {
+			t.Errorf("expected %s from another worker to be rejected, got %d: %s", path, rr.Code, rr.Body.String())
+		}
+		rr = doRequest(t, r, "POST", path, map[string]any{"job_id": job.ID})
+		if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "lease_mismatch") {
+			t.Errorf("expected %s naming no worker to be rejected, got %d: %s", path, rr.Code, rr.Body.String())
+		}
+	}
+
+	rr = doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": job.ID, "worker_id": "worker-1"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected ack from the lease holder to succeed, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if held := mb.WorkerJobs("worker-1"); len(held) != 0 {
+		t.Errorf("expected worker-1 to hold no jobs after ack, got %d", len(held))
+	}
+	stats, _ = mb.Stats(context.Background())
+	if stats.WorkerCount != 1 {
+		t.Errorf("expected 1 worker, got %d", stats.WorkerCount)
+	}
+}
+
+func TestRetryJob(t *testing.T) {
+	mb := newTestBackend()
+	ctx := context.Background()
+
+	job, _ := mb.EnqueueJob(ctx, &EnqueueRequest{Type: "email.send"})
+	if _, err := mb.RetryJob(ctx, job.ID); err == nil {
+		t.Error("expected an available job not to be retryable")
+	}
+
+	if _, err := mb.CancelJob(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	retried, err := mb.RetryJob(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retried.State != StateAvailable || retried.Attempt != 0 {
+		t.Errorf("expected the cancelled job to be available again, got %s attempt %d", retried.State, retried.Attempt)
+	}
+	jobs, _ := mb.Fetch(ctx, FetchRequest{})
+	if len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Errorf("expected the retried job to be fetchable, got %+v", jobs)
+	}
+
+	if _, err := mb.RetryJob(ctx, "missing"); err.(*RequestError).Status != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown job, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package backends
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Conflict strategies for UniquePolicy.OnConflict.
+const (
+	OnConflictReject                = "reject"
+	OnConflictReplace               = "replace"
+	OnConflictReplaceExceptSchedule = "replace_except_schedule"
+	OnConflictIgnore                = "ignore"
+)
+
+// UniquePolicy is the OJS uniqueness policy attached to a job.
+type UniquePolicy struct {
+	Keys       []string `json:"keys,omitempty"`
+	ArgsKeys   []string `json:"args_keys,omitempty"`
+	MetaKeys   []string `json:"meta_keys,omitempty"`
+	Period     string   `json:"period,omitempty"`
+	States     []string `json:"states,omitempty"`
+	OnConflict string   `json:"on_conflict,omitempty"`
+}
+
+// uniqueEntry is the index record for the latest job holding a fingerprint.
+type uniqueEntry struct {
+	jobID     string
+	createdAt time.Time
+}
+
+// normalize fills in defaults and validates the policy.
+func (u *UniquePolicy) normalize() error {
+	if !slices.Contains(u.Keys, "type") {
+		u.Keys = append([]string{"type"}, u.Keys...)
+	}
+	for _, k := range u.Keys {
+		switch k {
+		case "type", "queue", "args", "meta":
+		default:
+			return &FieldError{Path: "unique.keys", Message: fmt.Sprintf("has unknown key %q", k)}
+		}
+	}
+	if slices.Contains(u.Keys, "meta") && len(u.MetaKeys) == 0 {
+		return &FieldError{Path: "unique.meta_keys", Message: `is required when keys includes "meta"`}
+	}
+	if u.Period != "" {
+		if _, err := parseDuration(u.Period); err != nil {
+			return &FieldError{Path: "unique.period", Message: "must be an ISO 8601 duration"}
+		}
+	}
+	if len(u.States) == 0 {
+		u.States = []string{StateAvailable, StateActive, StateScheduled, StateRetryable, StatePending}
+	}
+	for _, s := range u.States {
+		if _, ok := validTransitions[s]; !ok {
+			return &FieldError{Path: "unique.states", Message: fmt.Sprintf("has unknown state %q", s)}
+		}
+	}
+	switch u.OnConflict {
+	case "":
+		u.OnConflict = OnConflictReject
+	case OnConflictReject, OnConflictReplace, OnConflictReplaceExceptSchedule, OnConflictIgnore:
+	default:
+		return &FieldError{Path: "unique.on_conflict", Message: fmt.Sprintf("has unknown strategy %q", u.OnConflict)}
+	}
+	return nil
+}
+
+// fingerprint returns a stable hash of the job's uniqueness dimensions.
+func (u *UniquePolicy) fingerprint(job *MemoryJob) (string, error) {
+	parts := map[string]any{"type": job.Type}
+
+	if slices.Contains(u.Keys, "queue") {
+		parts["queue"] = job.Queue
+	}
+	if slices.Contains(u.Keys, "args") {
+		var args []any
+		if err := json.Unmarshal(job.Args, &args); err != nil {
+			return "", fmt.Errorf("args must be an array: %w", err)
+		}
+		if len(u.ArgsKeys) > 0 {
+			for i, a := range args {
+				if obj, ok := a.(map[string]any); ok {
+					args[i] = pickKeys(obj, u.ArgsKeys)
+				}
+			}
+		}
+		parts["args"] = args
+	}
+	if slices.Contains(u.Keys, "meta") {
+		var meta map[string]any
+		if job.Meta != nil {
+			if err := json.Unmarshal(job.Meta, &meta); err != nil {
+				return "", fmt.Errorf("meta must be an object: %w", err)
+			}
+		}
+		parts["meta"] = pickKeys(meta, u.MetaKeys)
+	}
+
+	// encoding/json sorts map keys, so the encoding is canonical.
+	b, err := json.Marshal(parts)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func pickKeys(obj map[string]any, keys []string) map[string]any {
+	out := make(map[string]any, len(keys))
+	for _, k := range keys {
+		if v, ok := obj[k]; ok {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// findDuplicate returns the existing job that conflicts with the given
+// fingerprint, if any. Stale index entries are dropped.
+// Must be called with m.mu held.
+func (m *MemoryBackend) findDuplicate(key string, policy *UniquePolicy, now time.Time) *MemoryJob {
+	entry, ok := m.unique[key]
+	if !ok {
+		return nil
+	}
+
+	existing, ok := m.jobs[entry.jobID]
+	if !ok {
+		delete(m.unique, key)
+		return nil
+	}
+	if policy.Period != "" {
+		period, _ := parseDuration(policy.Period)
+		if now.Sub(entry.createdAt) >= period {
+			return nil
+		}
+	}
+	if !slices.Contains(policy.States, existing.State) {
+		return nil
+	}
+	return existing
+}
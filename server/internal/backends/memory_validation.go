@@ -0,0 +1,141 @@
+package backends
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SpecVersion is the OJS specification version jobs conform to.
+const SpecVersion = "1.0.0-rc.1"
+
+// FieldError describes one invalid field of a request. Path is the
+// dotted path of the field, such as "options.retry.max_attempts".
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string { return e.Path + " " + e.Message }
+
+// fieldErrors collects every invalid field of a request.
+type fieldErrors []FieldError
+
+// add records err against the field at path. A *FieldError keeps its own
+// path, nested under path.
+func (errs *fieldErrors) add(path string, err error) {
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		if fe.Path != "" {
+			path += "." + fe.Path
+		}
+		*errs = append(*errs, FieldError{Path: path, Message: fe.Message})
+		return
+	}
+	*errs = append(*errs, FieldError{Path: path, Message: err.Error()})
+}
+
+// err returns a validation_error listing the invalid fields, or nil if
+// there are none.
+func (errs fieldErrors) err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i := range errs {
+		msgs[i] = errs[i].Error()
+	}
+	return &RequestError{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "validation_error",
+		Message: "Invalid job: " + strings.Join(msgs, "; ") + ".",
+		Details: map[string]any{"details": []FieldError(errs)},
+	}
+}
+
+// isJSONKind reports whether raw is a JSON value starting with open, '['
+// for an array or '{' for an object.
+func isJSONKind(raw json.RawMessage, open byte) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && raw[0] == open && json.Valid(raw)
+}
+
+// knownOptions are the JSON names of the fields of EnqueueOptions.
+var knownOptions = jsonFieldNames(reflect.TypeOf(EnqueueOptions{}))
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// UnmarshalJSON decodes the options, noting any that OJS does not define
+// so that enqueue can reject them.
+func (o *EnqueueOptions) UnmarshalJSON(data []byte) error {
+	type plain EnqueueOptions
+	if err := json.Unmarshal(data, (*plain)(o)); err != nil {
+		// The decoder does not add the path to errors from UnmarshalJSON.
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			typeErr.Field = "options." + typeErr.Field
+		}
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	o.unknown = nil
+	for name := range fields {
+		if !knownOptions[name] {
+			o.unknown = append(o.unknown, name)
+		}
+	}
+	sort.Strings(o.unknown)
+	return nil
+}
+
+// decodeError converts an error from decoding a request body into an OJS
+// error. A value of the wrong type is a validation_error naming the field;
+// anything else is malformed JSON.
+func decodeError(err error) *RequestError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return (fieldErrors{{
+			Path:    typeErr.Field,
+			Message: fmt.Sprintf("must be of type %s", jsonTypeName(typeErr.Type)),
+		}}).err().(*RequestError)
+	}
+	return &RequestError{Status: http.StatusBadRequest, Code: "invalid_request", Message: "Invalid JSON: " + err.Error()}
+}
+
+// jsonTypeName names the JSON type a Go type decodes from.
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
@@ -0,0 +1,431 @@
+package backends
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/openjobspec/ojs-playground/server/internal/sse"
+)
+
+// Workflow types.
+const (
+	WorkflowChain = "chain" // steps run one after another
+	WorkflowGroup = "group" // steps run in parallel
+)
+
+// Workflow states.
+const (
+	WorkflowRunning   = "running"
+	WorkflowCompleted = "completed"
+	WorkflowFailed    = "failed"
+)
+
+// stepPending is the state of a workflow step whose job has not been
+// enqueued yet.
+const stepPending = "pending"
+
+// Workflow callback names.
+const (
+	CallbackOnComplete = "on_complete"
+	CallbackOnFailure  = "on_failure"
+)
+
+// WorkflowRequest is the body of POST /ojs/v1/workflows.
+type WorkflowRequest struct {
+	Type      string             `json:"type"`
+	Name      string             `json:"name,omitempty"`
+	Steps     []*EnqueueRequest  `json:"steps"`
+	Callbacks *WorkflowCallbacks `json:"callbacks,omitempty"`
+}
+
+// WorkflowCallbacks are jobs enqueued once a workflow finishes. OnComplete
+// runs whatever the outcome; OnFailure runs only if a step was discarded
+// or cancelled.
+type WorkflowCallbacks struct {
+	OnComplete *EnqueueRequest `json:"on_complete,omitempty"`
+	OnFailure  *EnqueueRequest `json:"on_failure,omitempty"`
+}
+
+// Workflow tracks the jobs making up a chain or group.
+type Workflow struct {
+	ID          string                   `json:"id"`
+	Type        string                   `json:"type"`
+	Name        string                   `json:"name,omitempty"`
+	State       string                   `json:"state"`
+	Steps       []*WorkflowStep          `json:"steps"`
+	Callbacks   map[string]*WorkflowStep `json:"callbacks,omitempty"`
+	CreatedAt   string                   `json:"created_at"`
+	CompletedAt string                   `json:"completed_at,omitempty"`
+}
+
+// WorkflowStep is one job of a workflow. Job IDs are assigned when the
+// workflow is submitted, so steps that have not run yet still have one.
+type WorkflowStep struct {
+	Index int    `json:"index"`
+	Type  string `json:"type"`
+	JobID string `json:"job_id"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"` // why the step's job could not be enqueued
+
+	job   *MemoryJob // prepared job, until it is enqueued
+	runAt time.Time
+}
+
+// progress counts the steps of the workflow that have finished.
+func (wf *Workflow) progress() (done, total int) {
+	for _, s := range wf.Steps {
+		if isTerminalState(s.State) {
+			done++
+		}
+	}
+	return done, len(wf.Steps)
+}
+
+// step returns the step or callback running the job.
+func (wf *Workflow) step(jobID string) *WorkflowStep {
+	for _, s := range wf.Steps {
+		if s.JobID == jobID {
+			return s
+		}
+	}
+	for _, s := range wf.Callbacks {
+		if s.JobID == jobID {
+			return s
+		}
+	}
+	return nil
+}
+
+// clone returns a copy of the workflow that is safe to use without m.mu.
+func (wf *Workflow) clone() *Workflow {
+	c := *wf
+	c.Steps = make([]*WorkflowStep, len(wf.Steps))
+	for i, s := range wf.Steps {
+		sc := *s
+		c.Steps[i] = &sc
+	}
+	if wf.Callbacks != nil {
+		c.Callbacks = make(map[string]*WorkflowStep, len(wf.Callbacks))
+		for name, s := range wf.Callbacks {
+			sc := *s
+			c.Callbacks[name] = &sc
+		}
+	}
+	return &c
+}
+
+// workflowEvent is an SSE event to broadcast once m.mu has been released.
+type workflowEvent struct {
+	eventType string
+	data      map[string]any
+}
+
+func progressEvent(eventType string, wf *Workflow, s *WorkflowStep) workflowEvent {
+	done, total := wf.progress()
+	data := map[string]any{
+		"workflow_id": wf.ID,
+		"type":        wf.Type,
+		"state":       wf.State,
+		"completed":   done,
+		"total":       total,
+	}
+	if s != nil {
+		data["job_id"] = s.JobID
+		data["step"] = s.Index
+		data["step_state"] = s.State
+	}
+	return workflowEvent{eventType: eventType, data: data}
+}
+
+// SubmitWorkflow validates a workflow definition and enqueues its first
+// steps: the first step of a chain, or every step of a group. Step job IDs
+// must not be taken by existing jobs or by other steps.
+func (m *MemoryBackend) SubmitWorkflow(req *WorkflowRequest) (*Workflow, error) {
+	if req.Type != WorkflowChain && req.Type != WorkflowGroup {
+		return nil, validationError(fmt.Sprintf("Field 'type' must be %q or %q.", WorkflowChain, WorkflowGroup))
+	}
+	if len(req.Steps) == 0 {
+		return nil, validationError("Field 'steps' must contain at least one job.")
+	}
+	if len(req.Steps) > MaxBatchSize {
+		return nil, validationError(fmt.Sprintf("Field 'steps' may contain at most %d jobs.", MaxBatchSize))
+	}
+
+	uid, _ := uuid.NewV7()
+	wf := &Workflow{
+		ID:        uid.String(),
+		Type:      req.Type,
+		Name:      req.Name,
+		State:     WorkflowRunning,
+		CreatedAt: nowFormatted(),
+	}
+
+	paths := make(map[string]string) // step job ID → step path
+	parentID := ""
+	for i, sr := range req.Steps {
+		path := fmt.Sprintf("steps[%d]", i)
+		s, err := m.prepareWorkflowStep(wf, sr, path)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := paths[s.JobID]; ok {
+			return nil, prefixPath(duplicateID(s.JobID), path)
+		}
+		paths[s.JobID] = path
+		s.Index = i
+		if wf.Type == WorkflowChain {
+			s.job.ParentID = parentID
+			parentID = s.JobID
+		}
+		wf.Steps = append(wf.Steps, s)
+	}
+
+	if req.Callbacks != nil {
+		callbacks := map[string]*EnqueueRequest{
+			CallbackOnComplete: req.Callbacks.OnComplete,
+			CallbackOnFailure:  req.Callbacks.OnFailure,
+		}
+		for name, cr := range callbacks {
+			if cr == nil {
+				continue
+			}
+			path := "callbacks." + name
+			s, err := m.prepareWorkflowStep(wf, cr, path)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := paths[s.JobID]; ok {
+				return nil, prefixPath(duplicateID(s.JobID), path)
+			}
+			paths[s.JobID] = path
+			s.Index = -1
+			if wf.Callbacks == nil {
+				wf.Callbacks = make(map[string]*WorkflowStep)
+			}
+			wf.Callbacks[name] = s
+		}
+	}
+
+	m.mu.Lock()
+	for id, path := range paths {
+		if _, ok := m.jobs[id]; ok {
+			m.mu.Unlock()
+			return nil, prefixPath(duplicateID(id), path)
+		}
+	}
+	m.workflows[wf.ID] = wf
+	var changes []transition
+	events := []workflowEvent{progressEvent(sse.EventWorkflowProgress, wf, nil)}
+	start := wf.Steps
+	if wf.Type == WorkflowChain {
+		start = start[:1]
+	}
+	for _, s := range start {
+		changes = append(changes, m.enqueueWorkflowStep(wf, s, &events)...)
+	}
+	snapshot := wf.clone()
+	m.mu.Unlock()
+
+	m.notify(changes)
+	m.broadcastWorkflowEvents(events)
+	return snapshot, nil
+}
+
+// prepareWorkflowStep validates the job for a step or callback. Field
+// names in validation errors are prefixed with path.
+func (m *MemoryBackend) prepareWorkflowStep(wf *Workflow, req *EnqueueRequest, path string) (*WorkflowStep, error) {
+	if req == nil {
+		return nil, validationError(fmt.Sprintf("Field '%s' must be a job.", path))
+	}
+	if req.Options != nil && req.Options.Unique != nil {
+		return nil, validationError(fmt.Sprintf("Field '%s.options.unique' is not supported in workflows.", path))
+	}
+	job, runAt, err := m.prepareJob(req)
+	if err != nil {
+		return nil, prefixPath(err, path)
+	}
+	job.WorkflowID = wf.ID
+	return &WorkflowStep{Type: job.Type, JobID: job.ID, State: stepPending, job: job, runAt: runAt}, nil
+}
+
+// prefixPath prefixes the message and field paths of a step's request
+// error with the step's path.
+func prefixPath(err error, path string) error {
+	if reqErr, ok := err.(*RequestError); ok {
+		reqErr.Message = path + ": " + reqErr.Message
+		if details, ok := reqErr.Details["details"].([]FieldError); ok {
+			for i := range details {
+				details[i].Path = path + "." + details[i].Path
+			}
+		}
+	}
+	return err
+}
+
+// enqueueWorkflowStep stores the prepared job of a step. A step whose job
+// cannot be stored is discarded, settling the workflow as if it had run.
+// Must be called with m.mu held.
+func (m *MemoryBackend) enqueueWorkflowStep(wf *Workflow, s *WorkflowStep, events *[]workflowEvent) []transition {
+	job := s.job
+	if job == nil {
+		return nil
+	}
+	s.job = nil
+	job.EnqueuedAt = nowFormatted()
+	_, _, changes, err := m.insertJob(job, s.runAt, false)
+	if err != nil {
+		s.State = StateDiscarded
+		s.Error = err.Error()
+		*events = append(*events, progressEvent(sse.EventWorkflowProgress, wf, s))
+		return m.settleStep(wf, s, events)
+	}
+	s.State = job.State
+	return changes
+}
+
+// advanceWorkflows records the state changes of workflow jobs and enqueues
+// the steps and callbacks they unlock. It returns the resulting changes,
+// which notify passes back through it until workflows stop advancing.
+// Must be called without m.mu held.
+func (m *MemoryBackend) advanceWorkflows(changes []transition) []transition {
+	relevant := false
+	for _, c := range changes {
+		if c.job.WorkflowID != "" {
+			relevant = true
+			break
+		}
+	}
+	if !relevant {
+		return nil
+	}
+
+	m.mu.Lock()
+	var more []transition
+	var events []workflowEvent
+	for _, c := range changes {
+		wf, ok := m.workflows[c.job.WorkflowID]
+		if !ok {
+			continue
+		}
+		s := wf.step(c.job.ID)
+		if s == nil {
+			continue
+		}
+		s.State = c.toState
+		events = append(events, progressEvent(sse.EventWorkflowProgress, wf, s))
+		more = append(more, m.settleStep(wf, s, &events)...)
+	}
+	m.mu.Unlock()
+
+	m.broadcastWorkflowEvents(events)
+	return more
+}
+
+// settleStep enqueues the next step of a chain once a step has finished,
+// or finishes the workflow if the step failed it or was the last to end.
+// Must be called with m.mu held.
+func (m *MemoryBackend) settleStep(wf *Workflow, s *WorkflowStep, events *[]workflowEvent) []transition {
+	if wf.State != WorkflowRunning || s.Index < 0 || !isTerminalState(s.State) {
+		return nil
+	}
+	switch {
+	case wf.Type == WorkflowChain && s.State == StateCompleted && s.Index+1 < len(wf.Steps):
+		return m.enqueueWorkflowStep(wf, wf.Steps[s.Index+1], events)
+	case wf.Type == WorkflowChain && s.State != StateCompleted:
+		return m.finishWorkflow(wf, WorkflowFailed, events)
+	}
+	if done, total := wf.progress(); done == total {
+		state := WorkflowCompleted
+		for _, step := range wf.Steps {
+			if step.State != StateCompleted {
+				state = WorkflowFailed
+			}
+		}
+		return m.finishWorkflow(wf, state, events)
+	}
+	return nil
+}
+
+// finishWorkflow settles a workflow and enqueues its callbacks.
+// Must be called with m.mu held.
+func (m *MemoryBackend) finishWorkflow(wf *Workflow, state string, events *[]workflowEvent) []transition {
+	wf.State = state
+	wf.CompletedAt = nowFormatted()
+
+	eventType := sse.EventWorkflowCompleted
+	if state == WorkflowFailed {
+		eventType = sse.EventWorkflowFailed
+	}
+	*events = append(*events, progressEvent(eventType, wf, nil))
+
+	var changes []transition
+	if s, ok := wf.Callbacks[CallbackOnComplete]; ok {
+		changes = append(changes, m.enqueueWorkflowStep(wf, s, events)...)
+	}
+	if s, ok := wf.Callbacks[CallbackOnFailure]; ok && state == WorkflowFailed {
+		changes = append(changes, m.enqueueWorkflowStep(wf, s, events)...)
+	}
+	return changes
+}
+
+func (m *MemoryBackend) broadcastWorkflowEvents(events []workflowEvent) {
+	for _, e := range events {
+		m.broadcast(e.eventType, "", "", e.data)
+	}
+}
+
+// GetWorkflow returns a copy of a workflow by ID.
+func (m *MemoryBackend) GetWorkflow(id string) (*Workflow, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	wf, ok := m.workflows[id]
+	if !ok {
+		return nil, false
+	}
+	return wf.clone(), true
+}
+
+// ListWorkflows returns copies of all workflows, newest first.
+func (m *MemoryBackend) ListWorkflows() []*Workflow {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	workflows := make([]*Workflow, 0, len(m.workflows))
+	for _, wf := range m.workflows {
+		workflows = append(workflows, wf.clone())
+	}
+	sort.Slice(workflows, func(i, j int) bool { return workflows[i].ID > workflows[j].ID })
+	return workflows
+}
+
+func (m *MemoryBackend) handleCreateWorkflow(w http.ResponseWriter, r *http.Request) {
+	var req WorkflowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON: "+err.Error())
+		return
+	}
+
+	wf, err := m.SubmitWorkflow(&req)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/ojs/v1/workflows/"+wf.ID)
+	writeJSON(w, http.StatusCreated, map[string]any{"workflow": wf})
+}
+
+func (m *MemoryBackend) handleGetWorkflow(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	wf, ok := m.GetWorkflow(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Workflow not found: "+id)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"workflow": wf})
+}
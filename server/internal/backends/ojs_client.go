@@ -0,0 +1,294 @@
+package backends
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ojsClientTimeout bounds each request an OJSClient makes, beyond any
+// time a fetch asks the server to wait for jobs.
+const ojsClientTimeout = 10 * time.Second
+
+// OJSClient is a JobBackend for a backend reached over OJS HTTP. Like
+// MemoryBackend, it reports the state changes it causes to onStateChange.
+type OJSClient struct {
+	baseURL       string
+	http          *http.Client
+	onStateChange StateChangeCallback
+}
+
+// NewOJSClient creates a client for the OJS server at baseURL, the URL the
+// /ojs/v1 endpoints are served under.
+func NewOJSClient(baseURL string, onStateChange StateChangeCallback) *OJSClient {
+	return &OJSClient{
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		http:          &http.Client{},
+		onStateChange: onStateChange,
+	}
+}
+
+// EnqueueJob sends POST /ojs/v1/jobs.
+func (c *OJSClient) EnqueueJob(ctx context.Context, req *EnqueueRequest) (*MemoryJob, error) {
+	job, _, err := c.EnqueueContext(ctx, req)
+	return job, err
+}
+
+// EnqueueContext sends POST /ojs/v1/jobs. It returns created=false when the
+// server answered 200 with an existing job rather than 201.
+func (c *OJSClient) EnqueueContext(ctx context.Context, req *EnqueueRequest) (*MemoryJob, bool, error) {
+	var resp struct {
+		Job *MemoryJob `json:"job"`
+	}
+	status, err := c.send(ctx, http.MethodPost, "/ojs/v1/jobs", req, &resp)
+	if err != nil {
+		return nil, false, err
+	}
+	if resp.Job == nil {
+		return nil, false, fmt.Errorf("POST /ojs/v1/jobs: response has no job")
+	}
+	created := status == http.StatusCreated
+	if created {
+		c.report(resp.Job, "")
+	}
+	return resp.Job, created, nil
+}
+
+// Job sends GET /ojs/v1/jobs/{id}.
+func (c *OJSClient) Job(ctx context.Context, id string) (*MemoryJob, error) {
+	return c.doJob(ctx, http.MethodGet, "/ojs/v1/jobs/"+url.PathEscape(id), nil)
+}
+
+// Jobs sends GET /ojs/v1/jobs with the filter as query parameters.
+func (c *OJSClient) Jobs(ctx context.Context, filter JobFilter) ([]*MemoryJob, int, error) {
+	query := url.Values{}
+	for name, value := range map[string]string{"state": filter.State, "type": filter.Type, "queue": filter.Queue} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		query.Set("offset", strconv.Itoa(filter.Offset))
+	}
+	path := "/ojs/v1/jobs"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp struct {
+		Jobs  []*MemoryJob `json:"jobs"`
+		Total int          `json:"total"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, 0, err
+	}
+	return resp.Jobs, resp.Total, nil
+}
+
+// CancelJob sends DELETE /ojs/v1/jobs/{id}.
+func (c *OJSClient) CancelJob(ctx context.Context, id string) (*MemoryJob, error) {
+	fromState := c.state(ctx, id)
+	job, err := c.doJob(ctx, http.MethodDelete, "/ojs/v1/jobs/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.report(job, fromState)
+	return job, nil
+}
+
+// RetryJob retries a dead job with POST /ojs/v1/dead/{id}/retry, the one
+// retry operation OJS defines.
+func (c *OJSClient) RetryJob(ctx context.Context, id string) (*MemoryJob, error) {
+	fromState := c.state(ctx, id)
+	job, err := c.doJob(ctx, http.MethodPost, "/ojs/v1/dead/"+url.PathEscape(id)+"/retry", nil)
+	if err != nil {
+		return nil, err
+	}
+	c.report(job, fromState)
+	return job, nil
+}
+
+// Fetch sends POST /ojs/v1/workers/fetch, allowing the request to run for
+// as long as the server may wait for jobs.
+func (c *OJSClient) Fetch(ctx context.Context, req FetchRequest) ([]*MemoryJob, error) {
+	wait := min(time.Duration(req.WaitMs)*time.Millisecond, maxFetchWait)
+	ctx, cancel := context.WithTimeout(ctx, ojsClientTimeout+max(wait, 0))
+	defer cancel()
+
+	var resp struct {
+		Jobs []*MemoryJob `json:"jobs"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/ojs/v1/workers/fetch", req, &resp); err != nil {
+		return nil, err
+	}
+	for _, job := range resp.Jobs {
+		c.report(job, StateAvailable)
+	}
+	return resp.Jobs, nil
+}
+
+// Ack sends POST /ojs/v1/workers/ack.
+func (c *OJSClient) Ack(ctx context.Context, req AckRequest) (*MemoryJob, error) {
+	job, err := c.doJob(ctx, http.MethodPost, "/ojs/v1/workers/ack", req)
+	if err != nil {
+		return nil, err
+	}
+	c.report(job, StateActive)
+	return job, nil
+}
+
+// Nack sends POST /ojs/v1/workers/nack.
+func (c *OJSClient) Nack(ctx context.Context, req NackRequest) (*MemoryJob, error) {
+	job, err := c.doJob(ctx, http.MethodPost, "/ojs/v1/workers/nack", req)
+	if err != nil {
+		return nil, err
+	}
+	c.report(job, StateActive)
+	return job, nil
+}
+
+// Queues sends GET /ojs/v1/queues.
+func (c *OJSClient) Queues(ctx context.Context) ([]QueueInfo, error) {
+	var resp struct {
+		Queues []QueueInfo `json:"queues"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/ojs/v1/queues", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Queues, nil
+}
+
+// SetQueuePaused sends POST /ojs/v1/queues/{name}/pause or resume.
+func (c *OJSClient) SetQueuePaused(ctx context.Context, name string, paused bool) error {
+	action := "/resume"
+	if paused {
+		action = "/pause"
+	}
+	return c.do(ctx, http.MethodPost, "/ojs/v1/queues/"+url.PathEscape(name)+action, nil, nil)
+}
+
+// state returns the current state of a job, or "" if it cannot be read.
+func (c *OJSClient) state(ctx context.Context, id string) string {
+	job, err := c.doJob(ctx, http.MethodGet, "/ojs/v1/jobs/"+url.PathEscape(id), nil)
+	if err != nil {
+		return ""
+	}
+	return job.State
+}
+
+// report passes a change to onStateChange, unless the job did not change
+// state.
+func (c *OJSClient) report(job *MemoryJob, fromState string) {
+	if c.onStateChange != nil && job.State != fromState {
+		c.onStateChange(job, fromState, job.State, "")
+	}
+}
+
+// doJob sends an OJS request whose response holds a job.
+func (c *OJSClient) doJob(ctx context.Context, method, path string, body any) (*MemoryJob, error) {
+	var resp struct {
+		Job *MemoryJob `json:"job"`
+	}
+	if err := c.do(ctx, method, path, body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Job == nil {
+		return nil, fmt.Errorf("%s %s: response has no job", method, path)
+	}
+	return resp.Job, nil
+}
+
+// do sends an OJS request and decodes the response into out. An OJS error
+// response is returned as a *RequestError. Unless ctx has a deadline of
+// its own, the request is bounded by ojsClientTimeout.
+func (c *OJSClient) do(ctx context.Context, method, path string, body, out any) error {
+	_, err := c.send(ctx, method, path, body, out)
+	return err
+}
+
+// send is like do, but also returns the status of a successful response.
+func (c *OJSClient) send(ctx context.Context, method, path string, body, out any) (int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ojsClientTimeout)
+		defer cancel()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/openjobspec+json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode >= 300 {
+		return 0, responseError(resp.StatusCode, data)
+	}
+	if out == nil {
+		return resp.StatusCode, nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return 0, fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// responseError converts an OJS error response into a *RequestError.
+// Fields of the error beyond its code and message, such as details or
+// existing_job_id, are kept as its Details.
+func responseError(status int, body []byte) *RequestError {
+	var resp struct {
+		Error map[string]any `json:"error"`
+	}
+	json.Unmarshal(body, &resp)
+
+	reqErr := &RequestError{Status: status}
+	reqErr.Code, _ = resp.Error["code"].(string)
+	reqErr.Message, _ = resp.Error["message"].(string)
+	for k, v := range resp.Error {
+		if k == "code" || k == "message" {
+			continue
+		}
+		if reqErr.Details == nil {
+			reqErr.Details = make(map[string]any)
+		}
+		reqErr.Details[k] = v
+	}
+	if reqErr.Code == "" {
+		reqErr.Code = "backend_error"
+	}
+	if reqErr.Message == "" {
+		reqErr.Message = fmt.Sprintf("Backend responded %d %s.", status, http.StatusText(status))
+	}
+	return reqErr
+}
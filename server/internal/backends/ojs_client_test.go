@@ -0,0 +1,130 @@
+package backends
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOJSClient(t *testing.T) {
+	mb := newTestBackend()
+	srv := httptest.NewServer(http.StripPrefix("/ojs/v1", mb.Router()))
+	defer srv.Close()
+
+	var changes []string
+	client := NewOJSClient(srv.URL+"/", func(job *MemoryJob, fromState, toState, reason string) {
+		changes = append(changes, fromState+"->"+toState)
+	})
+	ctx := context.Background()
+
+	job, err := client.EnqueueJob(ctx, &EnqueueRequest{Type: "email.send", Args: json.RawMessage(`[1]`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored, ok := mb.GetJob(job.ID); !ok || stored.State != StateAvailable {
+		t.Fatalf("expected the job to reach the backend, got %+v", stored)
+	}
+
+	if _, err := client.CancelJob(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"->available", "available->cancelled"}; strings.Join(changes, ",") != strings.Join(want, ",") {
+		t.Errorf("expected changes %v, got %v", want, changes)
+	}
+
+	_, err = client.CancelJob(ctx, job.ID)
+	reqErr, ok := err.(*RequestError)
+	if !ok || reqErr.Status != http.StatusConflict {
+		t.Errorf("expected the backend's 409 to be returned, got %v", err)
+	}
+	_, err = client.EnqueueJob(ctx, &EnqueueRequest{Args: json.RawMessage(`[]`)})
+	if reqErr, ok := err.(*RequestError); !ok || reqErr.Code != "validation_error" || reqErr.Details["details"] == nil {
+		t.Errorf("expected a validation_error with details, got %v", err)
+	}
+
+	unique := func(onConflict string) *EnqueueRequest {
+		return &EnqueueRequest{Type: "report", Args: json.RawMessage(`[]`), Options: &EnqueueOptions{
+			Unique: &UniquePolicy{Keys: []string{"type"}, OnConflict: onConflict},
+		}}
+	}
+	first, created, err := client.EnqueueContext(ctx, unique("ignore"))
+	if err != nil || !created {
+		t.Fatalf("expected a new job, got %v %v", created, err)
+	}
+	again, created, err := client.EnqueueContext(ctx, unique("ignore"))
+	if err != nil || created || again.ID != first.ID {
+		t.Errorf("expected the existing job, got %+v %v %v", again, created, err)
+	}
+	_, err = client.EnqueueJob(ctx, unique("reject"))
+	if reqErr, ok := err.(*RequestError); !ok || reqErr.Details["existing_job_id"] != first.ID {
+		t.Errorf("expected the conflict to name the existing job, got %v", err)
+	}
+}
+
+func TestJobBackendsInterchangeable(t *testing.T) {
+	remote := newTestBackend()
+	srv := httptest.NewServer(http.StripPrefix("/ojs/v1", remote.Router()))
+	defer srv.Close()
+
+	local := newTestBackend()
+	manager := NewManager("memory")
+	manager.Register(local)
+	active, err := manager.ActiveJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, b := range map[string]JobBackend{"memory": active, "ojs": NewOJSClient(srv.URL, nil)} {
+		t.Run(name, func(t *testing.T) { testJobBackend(t, b) })
+	}
+}
+
+// testJobBackend runs the same job operations against any backend.
+func testJobBackend(t *testing.T, b JobBackend) {
+	ctx := context.Background()
+	first, err := b.EnqueueJob(ctx, &EnqueueRequest{Type: "a", Args: json.RawMessage(`[]`), Options: &EnqueueOptions{Queue: "q"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := b.EnqueueJob(ctx, &EnqueueRequest{Type: "b", Args: json.RawMessage(`[]`), Options: &EnqueueOptions{Queue: "q"}})
+
+	jobs, total, err := b.Jobs(ctx, JobFilter{Queue: "q", Limit: 1})
+	if err != nil || total != 2 || len(jobs) != 1 || jobs[0].ID != second.ID {
+		t.Fatalf("expected the newest of 2 jobs, got %d %+v %v", total, jobs, err)
+	}
+	if job, err := b.Job(ctx, first.ID); err != nil || job.Type != "a" {
+		t.Fatalf("expected job a, got %+v %v", job, err)
+	}
+	if _, err := b.Job(ctx, "missing"); err == nil || err.(*RequestError).Status != http.StatusNotFound {
+		t.Errorf("expected a 404, got %v", err)
+	}
+
+	fetched, err := b.Fetch(ctx, FetchRequest{Queues: []string{"q"}, Count: 2, WorkerID: "w1"})
+	if err != nil || len(fetched) != 2 {
+		t.Fatalf("expected 2 jobs, got %+v %v", fetched, err)
+	}
+	if _, err := b.Ack(ctx, AckRequest{JobID: first.ID, WorkerID: "w2"}); err == nil || err.(*RequestError).Code != "lease_mismatch" {
+		t.Errorf("expected lease_mismatch, got %v", err)
+	}
+	if job, err := b.Ack(ctx, AckRequest{JobID: first.ID, WorkerID: "w1", Result: json.RawMessage(`1`)}); err != nil || job.State != StateCompleted {
+		t.Errorf("expected a completed job, got %+v %v", job, err)
+	}
+	if job, err := b.Nack(ctx, NackRequest{JobID: second.ID, WorkerID: "w1"}); err != nil || job.State != StateRetryable {
+		t.Errorf("expected a retryable job, got %+v %v", job, err)
+	}
+
+	if err := b.SetQueuePaused(ctx, "q", true); err != nil {
+		t.Fatal(err)
+	}
+	queues, err := b.Queues(ctx)
+	if err != nil || len(queues) != 1 || queues[0].Name != "q" || !queues[0].Paused {
+		t.Errorf("expected paused queue q, got %+v %v", queues, err)
+	}
+	b.EnqueueJob(ctx, &EnqueueRequest{Type: "c", Args: json.RawMessage(`[]`), Options: &EnqueueOptions{Queue: "q"}})
+	if fetched, _ := b.Fetch(ctx, FetchRequest{Queues: []string{"q"}}); len(fetched) != 0 {
+		t.Errorf("expected nothing from a paused queue, got %+v", fetched)
+	}
+}
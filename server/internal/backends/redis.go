@@ -0,0 +1,727 @@
+package backends
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces every key the Redis backend writes.
+const redisKeyPrefix = "ojs:"
+
+// fetchPollInterval is how often a waiting fetch on a persistent backend
+// looks for jobs again.
+const fetchPollInterval = 100 * time.Millisecond
+
+// Keys of the Redis backend. Each job is a hash; the sets index them.
+const (
+	redisJobsKey      = redisKeyPrefix + "jobs"      // sorted set: job ID by creation time
+	redisQueuesKey    = redisKeyPrefix + "queues"    // set: queue names
+	redisPausedKey    = redisKeyPrefix + "paused"    // set: paused queue names
+	redisScheduledKey = redisKeyPrefix + "scheduled" // sorted set: scheduled or retryable job ID by due time
+	redisActiveKey    = redisKeyPrefix + "active"    // sorted set: active job ID by lease deadline
+	redisJobPrefix    = redisKeyPrefix + "job:"      // hash per job
+	redisQueuePrefix  = redisKeyPrefix + "queue:"    // sorted set per queue: available job ID by queueScore
+)
+
+// The scripts below make each state change atomic. Those acting on one job
+// report the outcome as {status, detail, ...}; see redisOutcome.
+var (
+	// redisFetchScript claims up to ARGV[1] jobs from the queues in
+	// KEYS[3:], skipping paused ones, and leases them to worker ARGV[3].
+	// ARGV[2] is the time in ms, ARGV[4] the formatted time and ARGV[5:]
+	// the queue names. It returns the claimed job IDs.
+	redisFetchScript = redis.NewScript(`
+local count = tonumber(ARGV[1])
+local now = tonumber(ARGV[2])
+local claimed = {}
+for i = 3, #KEYS do
+  if #claimed >= count then break end
+  if redis.call('SISMEMBER', KEYS[1], ARGV[i + 2]) == 0 then
+    while #claimed < count do
+      local popped = redis.call('ZPOPMIN', KEYS[i])
+      if #popped == 0 then break end
+      local id = popped[1]
+      local key = '` + redisJobPrefix + `' .. id
+      local limits = redis.call('HMGET', key, 'lease_ms', 'exec_ms')
+      local deadline = now + tonumber(limits[1])
+      local exec = tonumber(limits[2])
+      if exec > 0 and now + exec < deadline then deadline = now + exec end
+      redis.call('HINCRBY', key, 'attempt', 1)
+      redis.call('HSET', key, 'state', 'active', 'started_at', ARGV[4], 'started_ms', now,
+        'worker_id', ARGV[3], 'lease_deadline', deadline)
+      redis.call('ZADD', KEYS[2], deadline, id)
+      claimed[#claimed + 1] = id
+    end
+  end
+end
+return claimed
+`)
+
+	// redisEnqueueScript stores job ARGV[1] with envelope ARGV[2], created
+	// at ARGV[9] ms, unless the ID is taken. A scheduled job goes into
+	// KEYS[4] and any other into its queue KEYS[5], scored ARGV[10].
+	redisEnqueueScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then return {'duplicate'} end
+redis.call('HSET', KEYS[1], 'job', ARGV[2], 'state', ARGV[3], 'queue', ARGV[4], 'priority', ARGV[5],
+  'attempt', 0, 'lease_ms', ARGV[6], 'exec_ms', ARGV[7], 'enqueued_at', ARGV[8])
+redis.call('ZADD', KEYS[2], ARGV[9], ARGV[1])
+redis.call('SADD', KEYS[3], ARGV[4])
+if ARGV[3] == 'scheduled' then
+  redis.call('ZADD', KEYS[4], ARGV[10], ARGV[1])
+else
+  redis.call('ZADD', KEYS[5], ARGV[10], ARGV[1])
+end
+return {'ok'}
+`)
+
+	// redisAckScript completes active job KEYS[1] held by worker ARGV[1],
+	// at formatted time ARGV[2] with result ARGV[3].
+	redisAckScript = redis.NewScript(`
+local job = redis.call('HMGET', KEYS[1], 'state', 'worker_id')
+if not job[1] then return {'not_found'} end
+if job[1] ~= 'active' then return {'invalid_state', job[1]} end
+local holder = job[2] or ''
+if holder ~= '' and holder ~= ARGV[1] then return {'lease_mismatch', holder, ARGV[1]} end
+redis.call('HSET', KEYS[1], 'state', 'completed', 'completed_at', ARGV[2], 'lease_deadline', '')
+if ARGV[3] ~= '' then redis.call('HSET', KEYS[1], 'result', ARGV[3]) end
+redis.call('ZREM', KEYS[2], ARGV[4])
+return {'ok', 'active'}
+`)
+
+	// redisFailScript records a failed attempt of active job KEYS[1], if it
+	// is still attempt ARGV[2] held by worker ARGV[1]. The job moves to
+	// state ARGV[3] with error ARGV[4]; a retryable job is due at ARGV[5]
+	// ms, formatted ARGV[6], and a discarded one is stamped ARGV[6].
+	redisFailScript = redis.NewScript(`
+local job = redis.call('HMGET', KEYS[1], 'state', 'worker_id', 'attempt')
+if not job[1] then return {'not_found'} end
+if job[1] ~= 'active' or job[3] ~= ARGV[2] then return {'invalid_state', job[1]} end
+local holder = job[2] or ''
+if holder ~= '' and holder ~= ARGV[1] then return {'lease_mismatch', holder, ARGV[1]} end
+redis.call('HSET', KEYS[1], 'state', ARGV[3], 'lease_deadline', '')
+if ARGV[4] ~= '' then redis.call('HSET', KEYS[1], 'error', ARGV[4]) end
+redis.call('ZREM', KEYS[2], ARGV[7])
+if ARGV[3] == 'retryable' then
+  redis.call('HSET', KEYS[1], 'next_attempt_at', ARGV[6])
+  redis.call('ZADD', KEYS[3], ARGV[5], ARGV[7])
+else
+  redis.call('HSET', KEYS[1], 'discarded_at', ARGV[6])
+end
+return {'ok', 'active'}
+`)
+
+	// redisHeartbeatScript extends the lease of active job KEYS[1] held by
+	// worker ARGV[1], as of ARGV[2] ms.
+	redisHeartbeatScript = redis.NewScript(`
+local job = redis.call('HMGET', KEYS[1], 'state', 'worker_id', 'lease_ms', 'exec_ms', 'started_ms')
+if not job[1] then return {'not_found'} end
+if job[1] ~= 'active' then return {'invalid_state', job[1]} end
+local holder = job[2] or ''
+if holder ~= '' and holder ~= ARGV[1] then return {'lease_mismatch', holder, ARGV[1]} end
+local deadline = tonumber(ARGV[2]) + tonumber(job[3])
+local exec = tonumber(job[4])
+if exec > 0 and tonumber(job[5]) + exec < deadline then deadline = tonumber(job[5]) + exec end
+redis.call('HSET', KEYS[1], 'lease_deadline', deadline)
+redis.call('ZADD', KEYS[2], deadline, ARGV[3])
+return {'ok', 'active'}
+`)
+
+	// redisCancelScript cancels job KEYS[1] at formatted time ARGV[1] if
+	// its state is one of ARGV[3:].
+	redisCancelScript = redis.NewScript(`
+local state = redis.call('HGET', KEYS[1], 'state')
+if not state then return {'not_found'} end
+local allowed = false
+for i = 3, #ARGV do
+  if ARGV[i] == state then allowed = true end
+end
+if not allowed then return {'invalid_state', state} end
+local queue = redis.call('HGET', KEYS[1], 'queue')
+redis.call('ZREM', '` + redisQueuePrefix + `' .. queue, ARGV[2])
+redis.call('ZREM', KEYS[2], ARGV[2])
+redis.call('ZREM', KEYS[3], ARGV[2])
+redis.call('HSET', KEYS[1], 'state', 'cancelled', 'cancelled_at', ARGV[1], 'lease_deadline', '')
+return {'ok', state}
+`)
+
+	// redisRetryScript makes a retryable, cancelled or discarded job
+	// KEYS[1] available again as of ARGV[1] ms, formatted ARGV[2].
+	redisRetryScript = redis.NewScript(`
+local job = redis.call('HMGET', KEYS[1], 'state', 'queue', 'priority')
+if not job[1] then return {'not_found'} end
+if job[1] == 'retryable' then
+  redis.call('ZREM', KEYS[2], ARGV[3])
+  redis.call('HSET', KEYS[1], 'next_attempt_at', '')
+elseif job[1] == 'cancelled' or job[1] == 'discarded' then
+  redis.call('HSET', KEYS[1], 'attempt', 0, 'error', '', 'cancelled_at', '', 'discarded_at', '')
+else
+  return {'invalid_state', job[1]}
+end
+redis.call('HSET', KEYS[1], 'state', 'available', 'enqueued_at', ARGV[2])
+redis.call('ZADD', '` + redisQueuePrefix + `' .. job[2], -tonumber(job[3]) * 1e13 + tonumber(ARGV[1]), ARGV[3])
+return {'ok', job[1]}
+`)
+
+	// redisPromoteScript moves scheduled and retryable jobs due by ARGV[1]
+	// ms into their queues, stamped ARGV[2]. It returns the ID and previous
+	// state of each. Members whose job is gone are dropped.
+	redisPromoteScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 100)
+local moved = {}
+for _, id in ipairs(due) do
+  local key = '` + redisJobPrefix + `' .. id
+  local job = redis.call('HMGET', key, 'state', 'queue', 'priority')
+  redis.call('ZREM', KEYS[1], id)
+  if job[1] then
+    redis.call('HSET', key, 'state', 'available', 'enqueued_at', ARGV[2], 'next_attempt_at', '')
+    redis.call('ZADD', '` + redisQueuePrefix + `' .. job[2], -tonumber(job[3]) * 1e13 + tonumber(ARGV[1]), id)
+    moved[#moved + 1] = id
+    moved[#moved + 1] = job[1]
+  end
+end
+return moved
+`)
+)
+
+// RedisBackend is an OJS Level 0 backend on Redis. Queues and scheduled
+// jobs are sorted sets, and every state change is a Lua script, so several
+// playgrounds or workers can share one Redis. It serves the same routes as
+// MemoryBackend; routes of the extensions it lacks answer 501.
+type RedisBackend struct {
+	client        *redis.Client
+	url           string
+	onStateChange StateChangeCallback
+	cancel        context.CancelFunc
+
+	mu           sync.RWMutex
+	validateArgs ArgsValidator
+}
+
+// NewRedisBackend connects to the Redis server at redisURL and starts the
+// background scheduler. Call Close to stop it.
+func NewRedisBackend(ctx context.Context, redisURL string, onStateChange StateChangeCallback) (*RedisBackend, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("connect to redis: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	b := &RedisBackend{
+		client:        client,
+		url:           redisURL,
+		onStateChange: onStateChange,
+		cancel:        cancel,
+	}
+	go b.runScheduler(runCtx)
+	return b, nil
+}
+
+// Name returns the backend name.
+func (b *RedisBackend) Name() string { return "redis" }
+
+// Type returns "redis".
+func (b *RedisBackend) Type() string { return "redis" }
+
+// URL returns the Redis URL, without its password.
+func (b *RedisBackend) URL() string {
+	u, err := url.Parse(b.url)
+	if err != nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+// Health reports whether Redis answers a PING.
+func (b *RedisBackend) Health(ctx context.Context) (*HealthStatus, error) {
+	if err := b.client.Ping(ctx).Err(); err != nil {
+		return &HealthStatus{Status: "error", Message: err.Error()}, nil
+	}
+	return &HealthStatus{Status: "ok"}, nil
+}
+
+// Stats returns job counts and queue depths.
+func (b *RedisBackend) Stats(ctx context.Context) (*BackendStats, error) {
+	total, err := b.client.ZCard(ctx, redisJobsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	active, err := b.client.ZRange(ctx, redisActiveKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	queues, err := b.Queues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &BackendStats{
+		TotalJobs:   int(total),
+		ActiveJobs:  len(active),
+		QueueDepths: make(map[string]int),
+	}
+	workers := make(map[string]bool)
+	for _, id := range active {
+		if worker, _ := b.client.HGet(ctx, redisJobPrefix+id, "worker_id").Result(); worker != "" {
+			workers[worker] = true
+		}
+	}
+	stats.WorkerCount = len(workers)
+	for _, q := range queues {
+		stats.QueueDepths[q.Name] = q.Available
+		if q.Paused {
+			stats.PausedQueues = append(stats.PausedQueues, q.Name)
+		}
+	}
+	return stats, nil
+}
+
+// Close stops the background scheduler and disconnects from Redis.
+func (b *RedisBackend) Close() error {
+	b.cancel()
+	return b.client.Close()
+}
+
+// Router returns a chi router implementing OJS HTTP endpoints.
+// Routes are relative (no /ojs/v1 prefix) — mount at /ojs/v1.
+func (b *RedisBackend) Router() chi.Router {
+	return coreRouter(b)
+}
+
+// SetArgsValidator makes enqueue reject jobs whose args do not match the
+// schema registered for their type.
+func (b *RedisBackend) SetArgsValidator(fn ArgsValidator) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.validateArgs = fn
+}
+
+// EnqueueJob validates and stores a new job.
+func (b *RedisBackend) EnqueueJob(ctx context.Context, req *EnqueueRequest) (*MemoryJob, error) {
+	b.mu.RLock()
+	validateArgs := b.validateArgs
+	b.mu.RUnlock()
+
+	job, runAt, err := buildJob(req, func(string) QueueConfig { return QueueConfig{} }, validateArgs)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCoreOptions(job, b.Type()); err != nil {
+		return nil, err
+	}
+
+	envelope, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	score := queueScore(job.Priority, now)
+	if job.State == StateScheduled {
+		score = float64(runAt.UnixMilli())
+	}
+	keys := []string{redisJobPrefix + job.ID, redisJobsKey, redisQueuesKey, redisScheduledKey, redisQueuePrefix + job.Queue}
+	args := []any{
+		job.ID, envelope, job.State, job.Queue, job.Priority,
+		job.heartbeatTimeout().Milliseconds(), job.executionTimeout().Milliseconds(),
+		job.EnqueuedAt, now.UnixMilli(), score,
+	}
+	res, err := redisEnqueueScript.Run(ctx, b.client, keys, args...).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	if res[0] == "duplicate" {
+		return nil, duplicateID(job.ID)
+	}
+
+	b.report(job, "", "")
+	return job, nil
+}
+
+// queueScore orders the available jobs of a queue: higher priorities
+// first, then oldest first. The scripts compute the same score.
+func queueScore(priority int, at time.Time) float64 {
+	return float64(-priority)*1e13 + float64(at.UnixMilli())
+}
+
+// Job returns a job by ID.
+func (b *RedisBackend) Job(ctx context.Context, id string) (*MemoryJob, error) {
+	fields, err := b.client.HGetAll(ctx, redisJobPrefix+id).Result()
+	if err != nil {
+		return nil, err
+	}
+	if fields["job"] == "" {
+		return nil, jobNotFound(id)
+	}
+	return decodeRedisJob(fields)
+}
+
+// decodeRedisJob rebuilds a job from its hash: the envelope stored at
+// enqueue, overlaid with the fields that change as it runs.
+func decodeRedisJob(fields map[string]string) (*MemoryJob, error) {
+	var job MemoryJob
+	if err := json.Unmarshal([]byte(fields["job"]), &job); err != nil {
+		return nil, fmt.Errorf("decode job: %w", err)
+	}
+	job.State = fields["state"]
+	job.Attempt, _ = strconv.Atoi(fields["attempt"])
+	job.EnqueuedAt = fields["enqueued_at"]
+	job.StartedAt = fields["started_at"]
+	job.CompletedAt = fields["completed_at"]
+	job.CancelledAt = fields["cancelled_at"]
+	job.DiscardedAt = fields["discarded_at"]
+	job.NextAttemptAt = fields["next_attempt_at"]
+	job.WorkerID = fields["worker_id"]
+	job.Result = rawField(fields["result"])
+	job.Error = rawField(fields["error"])
+	job.LeaseExpiresAt = ""
+	if ms, err := strconv.ParseFloat(fields["lease_deadline"], 64); err == nil {
+		job.LeaseExpiresAt = formatTime(time.UnixMilli(int64(ms)))
+	}
+	return &job, nil
+}
+
+// rawField returns a stored JSON value, or nil if there is none.
+func rawField(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	return json.RawMessage(s)
+}
+
+// Jobs returns the jobs matching filter, newest first, and the total
+// number that matched.
+func (b *RedisBackend) Jobs(ctx context.Context, filter JobFilter) ([]*MemoryJob, int, error) {
+	ids, err := b.client.ZRevRange(ctx, redisJobsKey, 0, -1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	jobs, err := b.loadJobs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var matched []*MemoryJob
+	for _, j := range jobs {
+		if (filter.State != "" && j.State != filter.State) ||
+			(filter.Type != "" && j.Type != filter.Type) ||
+			(filter.Queue != "" && j.Queue != filter.Queue) {
+			continue
+		}
+		matched = append(matched, j)
+	}
+
+	total := len(matched)
+	if filter.Offset > len(matched) {
+		filter.Offset = len(matched)
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total, nil
+}
+
+// loadJobs reads the jobs with the given IDs, in order, skipping any that
+// no longer exist.
+func (b *RedisBackend) loadJobs(ctx context.Context, ids []string) ([]*MemoryJob, error) {
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	_, err := b.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.HGetAll(ctx, redisJobPrefix+id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*MemoryJob, 0, len(ids))
+	for _, cmd := range cmds {
+		fields := cmd.Val()
+		if fields["job"] == "" {
+			continue
+		}
+		job, err := decodeRedisJob(fields)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// CancelJob cancels a job that has not finished.
+func (b *RedisBackend) CancelJob(ctx context.Context, id string) (*MemoryJob, error) {
+	args := []any{nowFormatted(), id}
+	for _, s := range statesAllowing(StateCancelled) {
+		args = append(args, s)
+	}
+	keys := []string{redisJobPrefix + id, redisScheduledKey, redisActiveKey}
+	return b.transition(ctx, redisCancelScript, keys, args, id, "cancel", "")
+}
+
+// RetryJob makes a failed or cancelled job available again now. A
+// retryable job keeps its attempt count; a cancelled or discarded one
+// starts over.
+func (b *RedisBackend) RetryJob(ctx context.Context, id string) (*MemoryJob, error) {
+	now := time.Now()
+	keys := []string{redisJobPrefix + id, redisScheduledKey}
+	return b.transition(ctx, redisRetryScript, keys, []any{now.UnixMilli(), formatTime(now), id}, id, "retry", "")
+}
+
+// Fetch claims up to req.Count available jobs from req.Queues, in order.
+// If there are none and req.WaitMs is set, it polls for jobs until the
+// wait is over or ctx is done.
+func (b *RedisBackend) Fetch(ctx context.Context, req FetchRequest) ([]*MemoryJob, error) {
+	if req.WaitMs < 0 {
+		return nil, validationError("Field 'wait_ms' must not be negative.")
+	}
+	if len(req.Queues) == 0 {
+		req.Queues = []string{"default"}
+	}
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	deadline := time.Now().Add(min(time.Duration(req.WaitMs)*time.Millisecond, maxFetchWait))
+
+	keys := []string{redisPausedKey, redisActiveKey}
+	for _, q := range req.Queues {
+		keys = append(keys, redisQueuePrefix+q)
+	}
+	for {
+		now := time.Now()
+		args := []any{req.Count, now.UnixMilli(), req.WorkerID, formatTime(now)}
+		for _, q := range req.Queues {
+			args = append(args, q)
+		}
+		ids, err := redisFetchScript.Run(ctx, b.client, keys, args...).StringSlice()
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > 0 {
+			jobs, err := b.loadJobs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			for _, job := range jobs {
+				b.report(job, StateAvailable, "")
+			}
+			return jobs, nil
+		}
+
+		if !now.Before(deadline) {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(min(fetchPollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// Ack completes an active job, keeping its result.
+func (b *RedisBackend) Ack(ctx context.Context, req AckRequest) (*MemoryJob, error) {
+	keys := []string{redisJobPrefix + req.JobID, redisActiveKey}
+	args := []any{req.WorkerID, nowFormatted(), string(req.Result), req.JobID}
+	return b.transition(ctx, redisAckScript, keys, args, req.JobID, "ack", "")
+}
+
+// Nack fails an active job, which is retried or discarded as its retry
+// policy says.
+func (b *RedisBackend) Nack(ctx context.Context, req NackRequest) (*MemoryJob, error) {
+	job, err := b.Job(ctx, req.JobID)
+	if err != nil {
+		return nil, err
+	}
+	return b.fail(ctx, job, req.WorkerID, req.Error, req.Requeue, "nack", "")
+}
+
+// fail records a failed attempt of an active job, like MemoryBackend's
+// failJob. The script applies it only if the job is still on the attempt
+// it was read at.
+func (b *RedisBackend) fail(ctx context.Context, job *MemoryJob, workerID string, jobErr json.RawMessage, requeue bool, op, reason string) (*MemoryJob, error) {
+	if job.State != StateActive {
+		return nil, invalidState(op, job.State)
+	}
+
+	now := time.Now()
+	targetState := StateRetryable
+	if job.Attempt >= job.MaxAttempts || job.Retry.isNonRetryable(jobErr) {
+		targetState = StateDiscarded
+	}
+	runAt := now
+	if targetState == StateRetryable && !requeue {
+		runAt = now.Add(job.Retry.backoff(job.Attempt))
+	}
+
+	keys := []string{redisJobPrefix + job.ID, redisActiveKey, redisScheduledKey}
+	args := []any{workerID, job.Attempt, targetState, string(jobErr), runAt.UnixMilli(), formatTime(runAt), job.ID}
+	failed, err := b.transition(ctx, redisFailScript, keys, args, job.ID, op, reason)
+	if err != nil {
+		return nil, err
+	}
+	if targetState == StateRetryable && !runAt.After(now) {
+		b.promoteDue(ctx, now)
+	}
+	return failed, nil
+}
+
+// Heartbeat extends the lease of an active job.
+func (b *RedisBackend) Heartbeat(ctx context.Context, req HeartbeatRequest) (*MemoryJob, error) {
+	keys := []string{redisJobPrefix + req.JobID, redisActiveKey}
+	args := []any{req.WorkerID, time.Now().UnixMilli(), req.JobID}
+	return b.transition(ctx, redisHeartbeatScript, keys, args, req.JobID, "heartbeat", "")
+}
+
+// Queues returns every queue that has held jobs or is paused, by name.
+func (b *RedisBackend) Queues(ctx context.Context) ([]QueueInfo, error) {
+	names, err := b.client.SUnion(ctx, redisQueuesKey, redisPausedKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	paused, err := b.client.SMembersMap(ctx, redisPausedKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	queues := make([]QueueInfo, 0, len(names))
+	for _, name := range names {
+		available, err := b.client.ZCard(ctx, redisQueuePrefix+name).Result()
+		if err != nil {
+			return nil, err
+		}
+		_, isPaused := paused[name]
+		queues = append(queues, QueueInfo{Name: name, Available: int(available), Paused: isPaused})
+	}
+	return queues, nil
+}
+
+// SetQueuePaused pauses or resumes a queue.
+func (b *RedisBackend) SetQueuePaused(ctx context.Context, name string, paused bool) error {
+	if paused {
+		return b.client.SAdd(ctx, redisPausedKey, name).Err()
+	}
+	return b.client.SRem(ctx, redisPausedKey, name).Err()
+}
+
+// transition runs a state change script on job id and reports the change.
+// op names the operation in errors.
+func (b *RedisBackend) transition(ctx context.Context, script *redis.Script, keys []string, args []any, id, op, reason string) (*MemoryJob, error) {
+	res, err := script.Run(ctx, b.client, keys, args...).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	fromState, err := redisOutcome(res, id, op)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := b.Job(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.State != fromState {
+		b.report(job, fromState, reason)
+	}
+	return job, nil
+}
+
+// redisOutcome interprets the {status, detail, ...} result of a state change
+// script, returning the job's previous state if the change was made.
+func redisOutcome(res []string, id, op string) (string, error) {
+	detail := ""
+	if len(res) > 1 {
+		detail = res[1]
+	}
+	switch res[0] {
+	case "ok":
+		return detail, nil
+	case "not_found":
+		return "", jobNotFound(id)
+	case "lease_mismatch":
+		return "", leaseMismatch(id, detail, res[2])
+	default:
+		return "", invalidState(op, detail)
+	}
+}
+
+// report passes a state change to onStateChange.
+func (b *RedisBackend) report(job *MemoryJob, fromState, reason string) {
+	if b.onStateChange != nil {
+		b.onStateChange(job, fromState, job.State, reason)
+	}
+}
+
+// runScheduler promotes due jobs and fails jobs whose lease ran out, every
+// schedulerInterval.
+func (b *RedisBackend) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			b.promoteDue(ctx, now)
+			b.reapExpired(ctx, now)
+		}
+	}
+}
+
+// promoteDue moves scheduled and retryable jobs whose time has come into
+// their queue.
+func (b *RedisBackend) promoteDue(ctx context.Context, now time.Time) {
+	res, err := redisPromoteScript.Run(ctx, b.client, []string{redisScheduledKey}, now.UnixMilli(), formatTime(now)).StringSlice()
+	if err != nil {
+		return
+	}
+	for i := 0; i+1 < len(res); i += 2 {
+		if job, err := b.Job(ctx, res[i]); err == nil {
+			b.report(job, res[i+1], "")
+		}
+	}
+}
+
+// reapExpired fails active jobs whose lease has run out.
+func (b *RedisBackend) reapExpired(ctx context.Context, now time.Time) {
+	ids, err := b.client.ZRangeByScore(ctx, redisActiveKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return
+	}
+	jobs, err := b.loadJobs(ctx, ids)
+	if err != nil {
+		return
+	}
+	for _, job := range jobs {
+		reason := "heartbeat"
+		if exec := job.executionTimeout(); exec > 0 {
+			if started, err := time.Parse(time.RFC3339, job.StartedAt); err == nil && !now.Before(started.Add(exec)) {
+				reason = "execution"
+			}
+		}
+		jobErr, _ := json.Marshal(map[string]any{
+			"type":    "timeout",
+			"message": fmt.Sprintf("Job exceeded its %s timeout.", reason),
+		})
+		b.fail(ctx, job, job.WorkerID, jobErr, false, "reap", "")
+	}
+}
@@ -0,0 +1,181 @@
+package backends
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisBackend(t *testing.T, mr *miniredis.Miniredis, onStateChange StateChangeCallback) *RedisBackend {
+	t.Helper()
+	b, err := NewRedisBackend(context.Background(), "redis://"+mr.Addr(), onStateChange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+func TestRedisBackendJobOperations(t *testing.T) {
+	b := newTestRedisBackend(t, miniredis.RunT(t), nil)
+	testJobBackend(t, b)
+}
+
+func TestRedisBackendPriorityAndSchedule(t *testing.T) {
+	b := newTestRedisBackend(t, miniredis.RunT(t), nil)
+	ctx := context.Background()
+
+	low, high := 1, 10
+	b.EnqueueJob(ctx, &EnqueueRequest{Type: "low", Options: &EnqueueOptions{Priority: &low}})
+	b.EnqueueJob(ctx, &EnqueueRequest{Type: "high", Options: &EnqueueOptions{Priority: &high}})
+	later, err := b.EnqueueJob(ctx, &EnqueueRequest{
+		Type:    "later",
+		Options: &EnqueueOptions{ScheduledAt: time.Now().Add(time.Hour).Format(time.RFC3339)},
+	})
+	if err != nil || later.State != StateScheduled {
+		t.Fatalf("expected a scheduled job, got %+v %v", later, err)
+	}
+
+	fetched, _ := b.Fetch(ctx, FetchRequest{Count: 3})
+	if len(fetched) != 2 || fetched[0].Type != "high" || fetched[1].Type != "low" {
+		t.Fatalf("expected high then low, got %+v", fetched)
+	}
+	if fetched[0].Attempt != 1 || fetched[0].LeaseExpiresAt == "" {
+		t.Errorf("expected a leased first attempt, got %+v", fetched[0])
+	}
+
+	// A member whose job hash is gone must not hold up the others.
+	b.client.ZAdd(ctx, redisScheduledKey, redis.Z{Score: 0, Member: "gone"})
+	b.promoteDue(ctx, time.Now().Add(2*time.Hour))
+	fetched, _ = b.Fetch(ctx, FetchRequest{})
+	if len(fetched) != 1 || fetched[0].ID != later.ID {
+		t.Errorf("expected the scheduled job once due, got %+v", fetched)
+	}
+	if n, _ := b.client.ZCard(ctx, redisScheduledKey).Result(); n != 0 {
+		t.Errorf("expected the stale member to be dropped, got %d left", n)
+	}
+}
+
+func TestRedisBackendRetries(t *testing.T) {
+	var changes []string
+	b := newTestRedisBackend(t, miniredis.RunT(t), func(job *MemoryJob, fromState, toState, reason string) {
+		changes = append(changes, fromState+"->"+toState)
+	})
+	ctx := context.Background()
+
+	maxAttempts := 2
+	job, _ := b.EnqueueJob(ctx, &EnqueueRequest{
+		Type:    "flaky",
+		Options: &EnqueueOptions{Retry: &retryPolicyRequest{MaxAttempts: &maxAttempts}},
+	})
+	_, err := b.EnqueueJob(ctx, &EnqueueRequest{
+		Type:    "flaky",
+		Options: &EnqueueOptions{Retry: &retryPolicyRequest{OnExhaustion: OnExhaustionDeadLetter}},
+	})
+	if reqErr, ok := err.(*RequestError); !ok || reqErr.Code != "validation_error" {
+		t.Errorf("expected dead-lettering to be rejected, got %v", err)
+	}
+
+	b.Fetch(ctx, FetchRequest{WorkerID: "w1"})
+	if _, err := b.Nack(ctx, NackRequest{JobID: job.ID}); err == nil || err.(*RequestError).Code != "lease_mismatch" {
+		t.Errorf("expected a nack naming no worker to be rejected, got %v", err)
+	}
+	failed, err := b.Nack(ctx, NackRequest{JobID: job.ID, WorkerID: "w1", Error: json.RawMessage(`{"message": "boom"}`)})
+	if err != nil || failed.State != StateRetryable || failed.NextAttemptAt == "" || string(failed.Error) == "" {
+		t.Fatalf("expected a retryable job, got %+v %v", failed, err)
+	}
+	if fetched, _ := b.Fetch(ctx, FetchRequest{}); len(fetched) != 0 {
+		t.Fatalf("expected the retry to wait for its backoff, got %+v", fetched)
+	}
+
+	b.promoteDue(ctx, time.Now().Add(time.Hour))
+	fetched, _ := b.Fetch(ctx, FetchRequest{WorkerID: "w1"})
+	if len(fetched) != 1 || fetched[0].Attempt != 2 {
+		t.Fatalf("expected the second attempt, got %+v", fetched)
+	}
+
+	// The lease runs out and the last attempt is discarded.
+	b.reapExpired(ctx, time.Now().Add(time.Hour))
+	discarded, _ := b.Job(ctx, job.ID)
+	if discarded.State != StateDiscarded || discarded.DiscardedAt == "" {
+		t.Fatalf("expected a discarded job, got %+v", discarded)
+	}
+
+	retried, err := b.RetryJob(ctx, job.ID)
+	if err != nil || retried.State != StateAvailable || retried.Attempt != 0 || retried.Error != nil {
+		t.Errorf("expected the job to start over, got %+v %v", retried, err)
+	}
+
+	want := "->available,available->active,active->retryable,retryable->available,available->active,active->discarded,discarded->available"
+	if got := strings.Join(changes, ","); got != want {
+		t.Errorf("expected changes %s, got %s", want, got)
+	}
+}
+
+func TestRedisBackendPersists(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	first := newTestRedisBackend(t, mr, nil)
+	job, _ := first.EnqueueJob(ctx, &EnqueueRequest{Type: "a", Args: json.RawMessage(`[1, "x"]`)})
+	if _, err := first.EnqueueJob(ctx, &EnqueueRequest{ID: job.ID, Type: "a"}); err == nil || err.(*RequestError).Code != "duplicate" {
+		t.Errorf("expected a duplicate ID to be rejected, got %v", err)
+	}
+	first.Close()
+
+	second := newTestRedisBackend(t, mr, nil)
+	fetched, _ := second.Fetch(ctx, FetchRequest{})
+	if len(fetched) != 1 || fetched[0].ID != job.ID || string(fetched[0].Args) != `[1,"x"]` {
+		t.Errorf("expected the job to survive a restart, got %+v", fetched)
+	}
+}
+
+func TestRedisBackendRouter(t *testing.T) {
+	b := newTestRedisBackend(t, miniredis.RunT(t), nil)
+	r := b.Router()
+
+	w := doRequest(t, r, "POST", "/jobs", map[string]any{"type": "email.send", "args": []any{"a"}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type":    "email.send",
+		"args":    []any{},
+		"options": map[string]any{"unique": map[string]any{"keys": []string{"type"}}},
+	})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected unique to be rejected, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}})
+	var resp struct {
+		Jobs []*MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Jobs) != 1 {
+		t.Fatalf("expected 1 job, got %s", w.Body.String())
+	}
+	w = doRequest(t, r, "POST", "/workers/heartbeat", map[string]any{"job_id": resp.Jobs[0].ID})
+	if w.Code != http.StatusOK {
+		t.Errorf("expected heartbeat to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(t, r, "POST", "/workflows", map[string]any{})
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 for workflows, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected healthy, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,699 @@
+package backends
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	_ "modernc.org/sqlite"
+)
+
+// sqliteBackendSchema creates the tables of the SQLite backend. Each job is
+// stored as its JSON envelope, next to the columns fetch and the scheduler
+// look it up by. Times are in Unix milliseconds.
+const sqliteBackendSchema = `
+	CREATE TABLE IF NOT EXISTS ojs_jobs (
+		id             TEXT PRIMARY KEY,
+		job            TEXT NOT NULL,
+		state          TEXT NOT NULL,
+		queue          TEXT NOT NULL,
+		priority       INTEGER NOT NULL,
+		run_at         INTEGER NOT NULL,
+		started_at     INTEGER,
+		last_beat      INTEGER,
+		lease_deadline INTEGER,
+		worker_id      TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_ojs_jobs_fetch ON ojs_jobs(queue, state, priority DESC, run_at);
+	CREATE INDEX IF NOT EXISTS idx_ojs_jobs_run_at ON ojs_jobs(state, run_at);
+	CREATE INDEX IF NOT EXISTS idx_ojs_jobs_lease ON ojs_jobs(state, lease_deadline);
+
+	CREATE TABLE IF NOT EXISTS ojs_paused_queues (
+		name TEXT PRIMARY KEY
+	);
+`
+
+// sqliteJobColumns are the columns scanSQLiteJob reads.
+const sqliteJobColumns = "job, run_at, started_at, last_beat"
+
+// SQLiteBackend is an OJS Level 0 backend on a SQLite database, so jobs
+// survive restarts without running a server. Every state change is a
+// transaction that holds the database's write lock, so a job is claimed by
+// one fetch only. It serves the same routes as MemoryBackend; routes of the
+// extensions it lacks answer 501.
+type SQLiteBackend struct {
+	db            *sql.DB
+	path          string
+	onStateChange StateChangeCallback
+	cancel        context.CancelFunc
+
+	mu           sync.RWMutex
+	validateArgs ArgsValidator
+}
+
+// NewSQLiteBackend opens or creates the database at dbPath and starts the
+// background scheduler. Call Close to stop it.
+func NewSQLiteBackend(ctx context.Context, dbPath string, onStateChange StateChangeCallback) (*SQLiteBackend, error) {
+	// _txlock=immediate takes the write lock when a transaction begins,
+	// rather than when it first writes.
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, sqliteBackendSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create sqlite backend tables: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	b := &SQLiteBackend{
+		db:            db,
+		path:          dbPath,
+		onStateChange: onStateChange,
+		cancel:        cancel,
+	}
+	go b.runScheduler(runCtx)
+	return b, nil
+}
+
+// Name returns the backend name.
+func (b *SQLiteBackend) Name() string { return "sqlite" }
+
+// Type returns "sqlite".
+func (b *SQLiteBackend) Type() string { return "sqlite" }
+
+// URL returns the path of the database file.
+func (b *SQLiteBackend) URL() string { return b.path }
+
+// Health reports whether the database answers a ping.
+func (b *SQLiteBackend) Health(ctx context.Context) (*HealthStatus, error) {
+	if err := b.db.PingContext(ctx); err != nil {
+		return &HealthStatus{Status: "error", Message: err.Error()}, nil
+	}
+	return &HealthStatus{Status: "ok"}, nil
+}
+
+// Stats returns job counts and queue depths.
+func (b *SQLiteBackend) Stats(ctx context.Context) (*BackendStats, error) {
+	stats := &BackendStats{QueueDepths: make(map[string]int)}
+	err := b.db.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE state = 'active'),
+			COUNT(DISTINCT worker_id) FILTER (WHERE state = 'active' AND worker_id != '')
+		FROM ojs_jobs
+	`).Scan(&stats.TotalJobs, &stats.ActiveJobs, &stats.WorkerCount)
+	if err != nil {
+		return nil, err
+	}
+
+	queues, err := b.Queues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range queues {
+		stats.QueueDepths[q.Name] = q.Available
+		if q.Paused {
+			stats.PausedQueues = append(stats.PausedQueues, q.Name)
+		}
+	}
+	return stats, nil
+}
+
+// Close stops the background scheduler and closes the database.
+func (b *SQLiteBackend) Close() error {
+	b.cancel()
+	return b.db.Close()
+}
+
+// Router returns a chi router implementing OJS HTTP endpoints.
+// Routes are relative (no /ojs/v1 prefix) — mount at /ojs/v1.
+func (b *SQLiteBackend) Router() chi.Router {
+	return coreRouter(b)
+}
+
+// SetArgsValidator makes enqueue reject jobs whose args do not match the
+// schema registered for their type.
+func (b *SQLiteBackend) SetArgsValidator(fn ArgsValidator) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.validateArgs = fn
+}
+
+// sqliteJob is a job as stored: its envelope, plus the times that are kept
+// outside it.
+type sqliteJob struct {
+	*MemoryJob
+	runAt time.Time // when it became available, or when a scheduled or retryable job is due
+	lease lease     // set while active
+}
+
+// sqliteQueryer is a *sql.DB or *sql.Tx.
+type sqliteQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// scanSQLiteJob reads the sqliteJobColumns of a row.
+func scanSQLiteJob(row interface{ Scan(dest ...any) error }) (*sqliteJob, error) {
+	var envelope string
+	var runAt int64
+	var startedAt, lastBeat sql.NullInt64
+	if err := row.Scan(&envelope, &runAt, &startedAt, &lastBeat); err != nil {
+		return nil, err
+	}
+
+	j := &sqliteJob{MemoryJob: &MemoryJob{}, runAt: time.UnixMilli(runAt)}
+	if err := json.Unmarshal([]byte(envelope), j.MemoryJob); err != nil {
+		return nil, fmt.Errorf("decode job: %w", err)
+	}
+	if startedAt.Valid && lastBeat.Valid {
+		j.lease = lease{startedAt: time.UnixMilli(startedAt.Int64), lastBeat: time.UnixMilli(lastBeat.Int64)}
+	}
+	return j, nil
+}
+
+// loadSQLiteJob reads job id.
+func loadSQLiteJob(ctx context.Context, q sqliteQueryer, id string) (*sqliteJob, error) {
+	j, err := scanSQLiteJob(q.QueryRowContext(ctx, "SELECT "+sqliteJobColumns+" FROM ojs_jobs WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, jobNotFound(id)
+	}
+	return j, err
+}
+
+// loadSQLiteJobs reads the jobs a query selects the sqliteJobColumns of.
+func loadSQLiteJobs(ctx context.Context, q sqliteQueryer, query string, args ...any) ([]*sqliteJob, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*sqliteJob
+	for rows.Next() {
+		j, err := scanSQLiteJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// save writes j back, or inserts it if insert is set.
+func (j *sqliteJob) save(ctx context.Context, tx *sql.Tx, insert bool) error {
+	envelope, err := json.Marshal(j.MemoryJob)
+	if err != nil {
+		return err
+	}
+	var startedAt, lastBeat, deadline any
+	if j.State == StateActive {
+		d, _ := j.lease.deadline(j.MemoryJob)
+		startedAt, lastBeat, deadline = j.lease.startedAt.UnixMilli(), j.lease.lastBeat.UnixMilli(), d.UnixMilli()
+	}
+
+	query := `UPDATE ojs_jobs SET job = ?, state = ?, queue = ?, priority = ?, run_at = ?,
+		started_at = ?, last_beat = ?, lease_deadline = ?, worker_id = ? WHERE id = ?`
+	if insert {
+		query = `INSERT INTO ojs_jobs (job, state, queue, priority, run_at, started_at, last_beat, lease_deadline, worker_id, id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	}
+	_, err = tx.ExecContext(ctx, query,
+		string(envelope), j.State, j.Queue, j.Priority, j.runAt.UnixMilli(),
+		startedAt, lastBeat, deadline, j.WorkerID, j.ID,
+	)
+	return err
+}
+
+// startLease leases j to workerID as of now.
+func (j *sqliteJob) startLease(workerID string, now time.Time) {
+	j.State = StateActive
+	j.StartedAt = nowFormatted()
+	j.Attempt++
+	j.WorkerID = workerID
+	j.lease = lease{startedAt: now, lastBeat: now}
+	d, _ := j.lease.deadline(j.MemoryJob)
+	j.LeaseExpiresAt = formatTime(d)
+}
+
+// fail records a failed attempt of an active job, like MemoryBackend's
+// failJob.
+func (j *sqliteJob) fail(jobErr json.RawMessage, requeue bool, now time.Time) {
+	targetState := StateRetryable
+	if j.Attempt >= j.MaxAttempts || j.Retry.isNonRetryable(jobErr) {
+		targetState = StateDiscarded
+	}
+
+	j.State = targetState
+	if targetState == StateDiscarded {
+		j.DiscardedAt = nowFormatted()
+	}
+	if jobErr != nil {
+		j.Error = jobErr
+	}
+	j.LeaseExpiresAt = ""
+	if targetState == StateRetryable {
+		var delay time.Duration
+		if !requeue {
+			delay = j.Retry.backoff(j.Attempt)
+		}
+		j.runAt = now.Add(delay)
+		j.NextAttemptAt = formatTime(j.runAt)
+	}
+}
+
+// makeAvailable puts j back in its queue as of now.
+func (j *sqliteJob) makeAvailable(now time.Time) {
+	j.State = StateAvailable
+	j.EnqueuedAt = nowFormatted()
+	j.NextAttemptAt = ""
+	j.runAt = now
+}
+
+// EnqueueJob validates and stores a new job.
+func (b *SQLiteBackend) EnqueueJob(ctx context.Context, req *EnqueueRequest) (*MemoryJob, error) {
+	b.mu.RLock()
+	validateArgs := b.validateArgs
+	b.mu.RUnlock()
+
+	job, runAt, err := buildJob(req, func(string) QueueConfig { return QueueConfig{} }, validateArgs)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCoreOptions(job, b.Type()); err != nil {
+		return nil, err
+	}
+
+	j := &sqliteJob{MemoryJob: job, runAt: time.Now()}
+	if job.State == StateScheduled {
+		j.runAt = runAt
+	}
+	err = b.inTx(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM ojs_jobs WHERE id = ?)", job.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return duplicateID(job.ID)
+		}
+		return j.save(ctx, tx, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.report(job, "", "")
+	return job, nil
+}
+
+// Job returns a job by ID.
+func (b *SQLiteBackend) Job(ctx context.Context, id string) (*MemoryJob, error) {
+	j, err := loadSQLiteJob(ctx, b.db, id)
+	if err != nil {
+		return nil, err
+	}
+	return j.MemoryJob, nil
+}
+
+// Jobs returns the jobs matching filter, newest first, and the total
+// number that matched.
+func (b *SQLiteBackend) Jobs(ctx context.Context, filter JobFilter) ([]*MemoryJob, int, error) {
+	var where []string
+	var args []any
+	if filter.State != "" {
+		where, args = append(where, "state = ?"), append(args, filter.State)
+	}
+	if filter.Type != "" {
+		where, args = append(where, "json_extract(job, '$.type') = ?"), append(args, filter.Type)
+	}
+	if filter.Queue != "" {
+		where, args = append(where, "queue = ?"), append(args, filter.Queue)
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := b.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ojs_jobs"+cond, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := -1 // no limit
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	found, err := loadSQLiteJobs(ctx, b.db,
+		"SELECT "+sqliteJobColumns+" FROM ojs_jobs"+cond+" ORDER BY id DESC LIMIT ? OFFSET ?",
+		append(args, limit, max(filter.Offset, 0))...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	jobs := make([]*MemoryJob, len(found))
+	for i, j := range found {
+		jobs[i] = j.MemoryJob
+	}
+	return jobs, total, nil
+}
+
+// CancelJob cancels a job that has not finished.
+func (b *SQLiteBackend) CancelJob(ctx context.Context, id string) (*MemoryJob, error) {
+	return b.update(ctx, id, "", func(j *sqliteJob, now time.Time) error {
+		if !isValidTransition(j.State, StateCancelled) {
+			return invalidState("cancel", j.State)
+		}
+		j.State = StateCancelled
+		j.CancelledAt = nowFormatted()
+		j.LeaseExpiresAt = ""
+		return nil
+	})
+}
+
+// RetryJob makes a failed or cancelled job available again now. A
+// retryable job keeps its attempt count; a cancelled or discarded one
+// starts over.
+func (b *SQLiteBackend) RetryJob(ctx context.Context, id string) (*MemoryJob, error) {
+	return b.update(ctx, id, "", func(j *sqliteJob, now time.Time) error {
+		switch j.State {
+		case StateRetryable:
+		case StateCancelled, StateDiscarded:
+			j.Attempt = 0
+			j.Error = nil
+			j.CancelledAt = ""
+			j.DiscardedAt = ""
+		default:
+			return invalidState("retry", j.State)
+		}
+		j.makeAvailable(now)
+		return nil
+	})
+}
+
+// Fetch claims up to req.Count available jobs from req.Queues, in order.
+// If there are none and req.WaitMs is set, it polls for jobs until the
+// wait is over or ctx is done.
+func (b *SQLiteBackend) Fetch(ctx context.Context, req FetchRequest) ([]*MemoryJob, error) {
+	if req.WaitMs < 0 {
+		return nil, validationError("Field 'wait_ms' must not be negative.")
+	}
+	if len(req.Queues) == 0 {
+		req.Queues = []string{"default"}
+	}
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	deadline := time.Now().Add(min(time.Duration(req.WaitMs)*time.Millisecond, maxFetchWait))
+
+	for {
+		now := time.Now()
+		fetched, err := b.claimJobs(ctx, req.Queues, req.Count, req.WorkerID, now)
+		if err != nil {
+			return nil, err
+		}
+		if len(fetched) > 0 {
+			for _, job := range fetched {
+				b.report(job, StateAvailable, "")
+			}
+			return fetched, nil
+		}
+
+		if !now.Before(deadline) {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(min(fetchPollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// claimJobs moves up to count available jobs from queues to active, leased
+// to workerID, skipping paused queues, in one transaction.
+func (b *SQLiteBackend) claimJobs(ctx context.Context, queues []string, count int, workerID string, now time.Time) ([]*MemoryJob, error) {
+	var fetched []*MemoryJob
+	err := b.inTx(ctx, func(tx *sql.Tx) error {
+		for _, q := range queues {
+			if len(fetched) >= count {
+				break
+			}
+			jobs, err := loadSQLiteJobs(ctx, tx, `
+				SELECT `+sqliteJobColumns+` FROM ojs_jobs
+				WHERE queue = ? AND state = 'available'
+					AND NOT EXISTS (SELECT 1 FROM ojs_paused_queues WHERE name = ?)
+				ORDER BY priority DESC, run_at, id
+				LIMIT ?
+			`, q, q, count-len(fetched))
+			if err != nil {
+				return err
+			}
+			for _, j := range jobs {
+				j.startLease(workerID, now)
+				if err := j.save(ctx, tx, false); err != nil {
+					return err
+				}
+				fetched = append(fetched, j.MemoryJob)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fetched, nil
+}
+
+// Ack completes an active job, keeping its result.
+func (b *SQLiteBackend) Ack(ctx context.Context, req AckRequest) (*MemoryJob, error) {
+	return b.update(ctx, req.JobID, "", func(j *sqliteJob, now time.Time) error {
+		if !isValidTransition(j.State, StateCompleted) {
+			return invalidState("ack", j.State)
+		}
+		if err := checkLeaseHolder(j.MemoryJob, req.WorkerID); err != nil {
+			return err
+		}
+		j.State = StateCompleted
+		j.CompletedAt = nowFormatted()
+		j.LeaseExpiresAt = ""
+		if req.Result != nil {
+			j.Result = req.Result
+		}
+		return nil
+	})
+}
+
+// Nack fails an active job, which is retried or discarded as its retry
+// policy says.
+func (b *SQLiteBackend) Nack(ctx context.Context, req NackRequest) (*MemoryJob, error) {
+	var j *sqliteJob
+	var changes []transition
+	err := b.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if j, err = loadSQLiteJob(ctx, tx, req.JobID); err != nil {
+			return err
+		}
+		if !isValidTransition(j.State, StateRetryable) {
+			return invalidState("nack", j.State)
+		}
+		if err := checkLeaseHolder(j.MemoryJob, req.WorkerID); err != nil {
+			return err
+		}
+		now := time.Now()
+		fromState := j.State
+		j.fail(req.Error, req.Requeue, now)
+		changes = append(changes, transition{job: j.MemoryJob.clone(), fromState: fromState})
+		if j.State == StateRetryable && !j.runAt.After(now) {
+			// A retry without backoff is available at once.
+			j.makeAvailable(now)
+			changes = append(changes, transition{job: j.MemoryJob, fromState: StateRetryable})
+		}
+		return j.save(ctx, tx, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range changes {
+		b.report(c.job, c.fromState, "")
+	}
+	return j.MemoryJob, nil
+}
+
+// Heartbeat extends the lease of an active job.
+func (b *SQLiteBackend) Heartbeat(ctx context.Context, req HeartbeatRequest) (*MemoryJob, error) {
+	return b.update(ctx, req.JobID, "", func(j *sqliteJob, now time.Time) error {
+		if j.State != StateActive {
+			return invalidState("heartbeat", j.State)
+		}
+		if err := checkLeaseHolder(j.MemoryJob, req.WorkerID); err != nil {
+			return err
+		}
+		j.lease.lastBeat = now
+		d, _ := j.lease.deadline(j.MemoryJob)
+		j.LeaseExpiresAt = formatTime(d)
+		return nil
+	})
+}
+
+// Queues returns every queue that has held jobs or is paused, by name.
+func (b *SQLiteBackend) Queues(ctx context.Context) ([]QueueInfo, error) {
+	rows, err := b.db.QueryContext(ctx, `
+		SELECT q.name,
+			(SELECT COUNT(*) FROM ojs_jobs WHERE queue = q.name AND state = 'available'),
+			EXISTS (SELECT 1 FROM ojs_paused_queues WHERE name = q.name)
+		FROM (SELECT DISTINCT queue AS name FROM ojs_jobs UNION SELECT name FROM ojs_paused_queues) q
+		ORDER BY q.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	queues := []QueueInfo{}
+	for rows.Next() {
+		var q QueueInfo
+		if err := rows.Scan(&q.Name, &q.Available, &q.Paused); err != nil {
+			return nil, err
+		}
+		queues = append(queues, q)
+	}
+	return queues, rows.Err()
+}
+
+// SetQueuePaused pauses or resumes a queue.
+func (b *SQLiteBackend) SetQueuePaused(ctx context.Context, name string, paused bool) error {
+	query := "DELETE FROM ojs_paused_queues WHERE name = ?"
+	if paused {
+		query = "INSERT OR IGNORE INTO ojs_paused_queues (name) VALUES (?)"
+	}
+	_, err := b.db.ExecContext(ctx, query, name)
+	return err
+}
+
+// inTx runs fn in a transaction, which holds the write lock throughout,
+// and commits it if fn succeeds.
+func (b *SQLiteBackend) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// update applies change to job id in a transaction and reports the state
+// change. If change returns an error, the job is left as it was.
+func (b *SQLiteBackend) update(ctx context.Context, id, reason string, change func(j *sqliteJob, now time.Time) error) (*MemoryJob, error) {
+	var j *sqliteJob
+	var fromState string
+	err := b.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if j, err = loadSQLiteJob(ctx, tx, id); err != nil {
+			return err
+		}
+		fromState = j.State
+		if err := change(j, time.Now()); err != nil {
+			return err
+		}
+		return j.save(ctx, tx, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if j.State != fromState {
+		b.report(j.MemoryJob, fromState, reason)
+	}
+	return j.MemoryJob, nil
+}
+
+// updateWhere applies change to every job a query selects the
+// sqliteJobColumns of, in one transaction, and reports the state changes.
+func (b *SQLiteBackend) updateWhere(ctx context.Context, change func(j *sqliteJob), query string, args ...any) error {
+	var changes []transition
+	err := b.inTx(ctx, func(tx *sql.Tx) error {
+		jobs, err := loadSQLiteJobs(ctx, tx, query, args...)
+		if err != nil {
+			return err
+		}
+		for _, j := range jobs {
+			fromState := j.State
+			change(j)
+			if err := j.save(ctx, tx, false); err != nil {
+				return err
+			}
+			changes = append(changes, transition{job: j.MemoryJob, fromState: fromState, toState: j.State})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		b.report(c.job, c.fromState, "")
+	}
+	return nil
+}
+
+// report passes a state change to onStateChange.
+func (b *SQLiteBackend) report(job *MemoryJob, fromState, reason string) {
+	if b.onStateChange != nil {
+		b.onStateChange(job, fromState, job.State, reason)
+	}
+}
+
+// runScheduler promotes due jobs and fails jobs whose lease ran out, every
+// schedulerInterval.
+func (b *SQLiteBackend) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := b.promoteDue(ctx, now); err != nil && ctx.Err() == nil {
+				slog.Warn("failed to promote due jobs", "backend", b.Type(), "err", err)
+			}
+			if err := b.reapExpired(ctx, now); err != nil && ctx.Err() == nil {
+				slog.Warn("failed to reap expired jobs", "backend", b.Type(), "err", err)
+			}
+		}
+	}
+}
+
+// promoteDue moves scheduled and retryable jobs whose time has come into
+// their queue.
+func (b *SQLiteBackend) promoteDue(ctx context.Context, now time.Time) error {
+	return b.updateWhere(ctx, func(j *sqliteJob) { j.makeAvailable(now) },
+		"SELECT "+sqliteJobColumns+" FROM ojs_jobs WHERE state IN ('scheduled', 'retryable') AND run_at <= ?",
+		now.UnixMilli())
+}
+
+// reapExpired fails active jobs whose lease has run out.
+func (b *SQLiteBackend) reapExpired(ctx context.Context, now time.Time) error {
+	return b.updateWhere(ctx, func(j *sqliteJob) {
+		_, reason := j.lease.deadline(j.MemoryJob)
+		jobErr, _ := json.Marshal(map[string]any{
+			"type":    "timeout",
+			"message": fmt.Sprintf("Job exceeded its %s timeout.", reason),
+		})
+		j.fail(jobErr, false, now)
+	}, "SELECT "+sqliteJobColumns+" FROM ojs_jobs WHERE state = 'active' AND lease_deadline <= ?", now.UnixMilli())
+}
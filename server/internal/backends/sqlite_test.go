@@ -0,0 +1,254 @@
+package backends
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestSQLiteBackend(t *testing.T, dbPath string, onStateChange StateChangeCallback) *SQLiteBackend {
+	t.Helper()
+	b, err := NewSQLiteBackend(context.Background(), dbPath, onStateChange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+func TestSQLiteBackendJobOperations(t *testing.T) {
+	b := newTestSQLiteBackend(t, filepath.Join(t.TempDir(), "backend.db"), nil)
+	testJobBackend(t, b)
+}
+
+func TestSQLiteBackendPriorityAndSchedule(t *testing.T) {
+	b := newTestSQLiteBackend(t, filepath.Join(t.TempDir(), "backend.db"), nil)
+	ctx := context.Background()
+
+	low, high := 1, 10
+	b.EnqueueJob(ctx, &EnqueueRequest{Type: "low", Options: &EnqueueOptions{Priority: &low}})
+	b.EnqueueJob(ctx, &EnqueueRequest{Type: "high", Options: &EnqueueOptions{Priority: &high}})
+	later, err := b.EnqueueJob(ctx, &EnqueueRequest{
+		Type:    "later",
+		Options: &EnqueueOptions{ScheduledAt: time.Now().Add(time.Hour).Format(time.RFC3339)},
+	})
+	if err != nil || later.State != StateScheduled {
+		t.Fatalf("expected a scheduled job, got %+v %v", later, err)
+	}
+
+	fetched, _ := b.Fetch(ctx, FetchRequest{Count: 3})
+	if len(fetched) != 2 || fetched[0].Type != "high" || fetched[1].Type != "low" {
+		t.Fatalf("expected high then low, got %+v", fetched)
+	}
+	if fetched[0].Attempt != 1 || fetched[0].LeaseExpiresAt == "" {
+		t.Errorf("expected a leased first attempt, got %+v", fetched[0])
+	}
+
+	b.promoteDue(ctx, time.Now().Add(2*time.Hour))
+	fetched, _ = b.Fetch(ctx, FetchRequest{})
+	if len(fetched) != 1 || fetched[0].ID != later.ID {
+		t.Errorf("expected the scheduled job once due, got %+v", fetched)
+	}
+}
+
+func TestSQLiteBackendRetries(t *testing.T) {
+	var changes []string
+	b := newTestSQLiteBackend(t, filepath.Join(t.TempDir(), "backend.db"), func(job *MemoryJob, fromState, toState, reason string) {
+		changes = append(changes, fromState+"->"+toState)
+	})
+	ctx := context.Background()
+
+	maxAttempts := 2
+	job, _ := b.EnqueueJob(ctx, &EnqueueRequest{
+		Type:    "flaky",
+		Options: &EnqueueOptions{Retry: &retryPolicyRequest{MaxAttempts: &maxAttempts}},
+	})
+	_, err := b.EnqueueJob(ctx, &EnqueueRequest{
+		Type:    "flaky",
+		Options: &EnqueueOptions{Retry: &retryPolicyRequest{OnExhaustion: OnExhaustionDeadLetter}},
+	})
+	if reqErr, ok := err.(*RequestError); !ok || reqErr.Code != "validation_error" {
+		t.Errorf("expected dead-lettering to be rejected, got %v", err)
+	}
+
+	b.Fetch(ctx, FetchRequest{WorkerID: "w1"})
+	if _, err := b.Nack(ctx, NackRequest{JobID: job.ID}); err == nil || err.(*RequestError).Code != "lease_mismatch" {
+		t.Errorf("expected a nack naming no worker to be rejected, got %v", err)
+	}
+	failed, err := b.Nack(ctx, NackRequest{JobID: job.ID, WorkerID: "w1", Error: json.RawMessage(`{"message": "boom"}`)})
+	if err != nil || failed.State != StateRetryable || failed.NextAttemptAt == "" || string(failed.Error) == "" {
+		t.Fatalf("expected a retryable job, got %+v %v", failed, err)
+	}
+	if fetched, _ := b.Fetch(ctx, FetchRequest{}); len(fetched) != 0 {
+		t.Fatalf("expected the retry to wait for its backoff, got %+v", fetched)
+	}
+
+	b.promoteDue(ctx, time.Now().Add(time.Hour))
+	fetched, _ := b.Fetch(ctx, FetchRequest{WorkerID: "w1"})
+	if len(fetched) != 1 || fetched[0].Attempt != 2 {
+		t.Fatalf("expected the second attempt, got %+v", fetched)
+	}
+	if _, err := b.Ack(ctx, AckRequest{JobID: job.ID, WorkerID: "w2"}); err == nil || err.(*RequestError).Code != "lease_mismatch" {
+		t.Errorf("expected another worker's ack to be rejected, got %v", err)
+	}
+
+	// The lease runs out and the last attempt is discarded.
+	b.reapExpired(ctx, time.Now().Add(time.Hour))
+	discarded, _ := b.Job(ctx, job.ID)
+	if discarded.State != StateDiscarded || discarded.DiscardedAt == "" || discarded.LeaseExpiresAt != "" {
+		t.Fatalf("expected a discarded job, got %+v", discarded)
+	}
+
+	retried, err := b.RetryJob(ctx, job.ID)
+	if err != nil || retried.State != StateAvailable || retried.Attempt != 0 || retried.Error != nil {
+		t.Errorf("expected the job to start over, got %+v %v", retried, err)
+	}
+
+	want := "->available,available->active,active->retryable,retryable->available,available->active,active->discarded,discarded->available"
+	if got := strings.Join(changes, ","); got != want {
+		t.Errorf("expected changes %s, got %s", want, got)
+	}
+}
+
+func TestSQLiteBackendRequeue(t *testing.T) {
+	var changes []string
+	b := newTestSQLiteBackend(t, filepath.Join(t.TempDir(), "backend.db"), func(job *MemoryJob, fromState, toState, reason string) {
+		changes = append(changes, fromState+"->"+toState)
+	})
+	ctx := context.Background()
+
+	job, _ := b.EnqueueJob(ctx, &EnqueueRequest{Type: "flaky"})
+	later, _ := b.EnqueueJob(ctx, &EnqueueRequest{
+		Type:    "later",
+		Options: &EnqueueOptions{ScheduledAt: time.Now().Add(time.Hour).Format(time.RFC3339)},
+	})
+	// The scheduled job falls due, but is left to the scheduler.
+	if _, err := b.db.ExecContext(ctx, "UPDATE ojs_jobs SET run_at = 0 WHERE id = ?", later.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	b.Fetch(ctx, FetchRequest{WorkerID: "w1"})
+	changes = nil
+	requeued, err := b.Nack(ctx, NackRequest{JobID: job.ID, WorkerID: "w1", Requeue: true})
+	if err != nil || requeued.State != StateAvailable || requeued.NextAttemptAt != "" {
+		t.Fatalf("expected the job available at once, got %+v %v", requeued, err)
+	}
+	if stored, _ := b.Job(ctx, later.ID); stored.State != StateScheduled {
+		t.Errorf("expected the other due job to be left alone, got %s", stored.State)
+	}
+	if want := "active->retryable,retryable->available"; strings.Join(changes, ",") != want {
+		t.Errorf("expected changes %s, got %s", want, strings.Join(changes, ","))
+	}
+}
+
+func TestSQLiteBackendClaimsOnce(t *testing.T) {
+	b := newTestSQLiteBackend(t, filepath.Join(t.TempDir(), "backend.db"), nil)
+	ctx := context.Background()
+
+	for range 20 {
+		b.EnqueueJob(ctx, &EnqueueRequest{Type: "a"})
+	}
+
+	var mu sync.Mutex
+	claimed := make(map[string]int)
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				fetched, err := b.Fetch(ctx, FetchRequest{Count: 3})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(fetched) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, j := range fetched {
+					claimed[j.ID]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(claimed) != 20 {
+		t.Errorf("expected all 20 jobs claimed, got %d", len(claimed))
+	}
+	for id, n := range claimed {
+		if n != 1 {
+			t.Errorf("expected job %s claimed once, got %d", id, n)
+		}
+	}
+}
+
+func TestSQLiteBackendPersists(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "backend.db")
+	ctx := context.Background()
+
+	first := newTestSQLiteBackend(t, dbPath, nil)
+	job, _ := first.EnqueueJob(ctx, &EnqueueRequest{Type: "a", Args: json.RawMessage(`[1, "x"]`)})
+	if _, err := first.EnqueueJob(ctx, &EnqueueRequest{ID: job.ID, Type: "a"}); err == nil || err.(*RequestError).Code != "duplicate" {
+		t.Errorf("expected a duplicate ID to be rejected, got %v", err)
+	}
+	first.SetQueuePaused(ctx, "paused", true)
+	first.Close()
+
+	second := newTestSQLiteBackend(t, dbPath, nil)
+	queues, _ := second.Queues(ctx)
+	if len(queues) != 2 || queues[0].Name != "default" || queues[0].Available != 1 || !queues[1].Paused {
+		t.Errorf("expected the queues to survive a restart, got %+v", queues)
+	}
+	fetched, _ := second.Fetch(ctx, FetchRequest{})
+	if len(fetched) != 1 || fetched[0].ID != job.ID || string(fetched[0].Args) != `[1,"x"]` {
+		t.Errorf("expected the job to survive a restart, got %+v", fetched)
+	}
+}
+
+func TestSQLiteBackendRouter(t *testing.T) {
+	b := newTestSQLiteBackend(t, filepath.Join(t.TempDir(), "backend.db"), nil)
+	r := b.Router()
+
+	w := doRequest(t, r, "POST", "/jobs", map[string]any{"type": "email.send", "args": []any{"a"}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(t, r, "POST", "/jobs", map[string]any{
+		"type":    "email.send",
+		"args":    []any{},
+		"options": map[string]any{"rate_limit": map[string]any{"key": "tenant", "concurrency": 1}},
+	})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected rate_limit to be rejected, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(t, r, "POST", "/workers/fetch", map[string]any{"queues": []string{"default"}, "worker_id": "w1"})
+	var resp struct {
+		Jobs []*MemoryJob `json:"jobs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Jobs) != 1 {
+		t.Fatalf("expected 1 job, got %s", w.Body.String())
+	}
+	w = doRequest(t, r, "POST", "/workers/heartbeat", map[string]any{"job_id": resp.Jobs[0].ID, "worker_id": "w1"})
+	if w.Code != http.StatusOK {
+		t.Errorf("expected heartbeat to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(t, r, "POST", "/workers/ack", map[string]any{"job_id": resp.Jobs[0].ID, "worker_id": "w1", "result": map[string]any{"ok": true}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"state":"completed"`) {
+		t.Errorf("expected ack to complete the job, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(t, r, "GET", "/dead", nil)
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 for the dead letter list, got %d", w.Code)
+	}
+}
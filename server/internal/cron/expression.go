@@ -0,0 +1,168 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// aliases maps the supported @-shorthands to their 5-field form.
+var aliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type field struct {
+	label string
+	min   int
+	max   int
+	names []string
+}
+
+var fields = []field{
+	{label: "minute", min: 0, max: 59},
+	{label: "hour", min: 0, max: 23},
+	{label: "day of month", min: 1, max: 31},
+	{label: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{label: "day of week", min: 0, max: 6, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// Schedule is a parsed 5-field cron expression.
+type Schedule struct {
+	minute, hour, dom, month, dow map[int]bool
+
+	// Standard cron semantics: when both day fields are restricted, a day
+	// matches if either one does.
+	domStar, dowStar bool
+}
+
+// Parse parses a standard 5-field cron expression or an @-alias.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "@") {
+		resolved, ok := aliases[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("unknown alias: %s", expr)
+		}
+		expr = resolved
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(parts))
+	}
+
+	sets := make([]map[int]bool, 5)
+	for i, f := range fields {
+		set, err := expandField(parts[i], f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.label, err)
+		}
+		sets[i] = set
+	}
+
+	return &Schedule{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: parts[2] == "*",
+		dowStar: parts[4] == "*",
+	}, nil
+}
+
+func expandField(s string, f field) (map[int]bool, error) {
+	s = strings.ToUpper(s)
+	for i, name := range f.names {
+		s = strings.ReplaceAll(s, name, strconv.Itoa(f.min+i))
+	}
+
+	values := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step value: %s", part[i+1:])
+			}
+			rng, step = part[:i], n
+		}
+
+		start, end := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			a, errA := strconv.Atoi(bounds[0])
+			b, errB := strconv.Atoi(bounds[1])
+			if errA != nil || errB != nil {
+				return nil, fmt.Errorf("invalid range: %s", rng)
+			}
+			if a < f.min || b > f.max || a > b {
+				return nil, fmt.Errorf("range out of bounds: %s", rng)
+			}
+			start, end = a, b
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil || n < f.min || n > f.max {
+				return nil, fmt.Errorf("value out of bounds: %s (%d-%d)", rng, f.min, f.max)
+			}
+			start, end = n, n
+		}
+
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// Next returns the first matching time strictly after t, in t's location.
+// It returns the zero time if nothing matches within five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !s.month[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hour[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom[t.Day()]
+	dow := s.dow[int(t.Weekday())]
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dow
+	case s.dowStar:
+		return dom
+	default:
+		return dom || dow
+	}
+}
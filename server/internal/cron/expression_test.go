@@ -0,0 +1,63 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"@fortnightly",
+	}
+	for _, expr := range cases {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	base := time.Date(2026, 3, 10, 8, 30, 15, 0, time.UTC) // Tuesday
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 10, 8, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 10, 8, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * SAT", time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 JAN *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Day-of-month and day-of-week are OR'ed when both are restricted
+		{"0 0 15 * FRI", time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		s, err := Parse(tc.expr)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tc.expr, err)
+			continue
+		}
+		if got := s.Next(base); !got.Equal(tc.want) {
+			t.Errorf("%q: expected %s, got %s", tc.expr, tc.want, got)
+		}
+	}
+}
+
+func TestNextImpossible(t *testing.T) {
+	s, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("expected zero time for Feb 31, got %s", got)
+	}
+}
@@ -0,0 +1,263 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	_ "time/tzdata" // timezones must resolve even without a system zoneinfo
+
+	"github.com/openjobspec/ojs-playground/server/internal/history"
+	"github.com/openjobspec/ojs-playground/server/internal/sse"
+)
+
+const tickInterval = time.Second
+
+// ErrNotFound is returned for operations on an unknown cron entry.
+var ErrNotFound = errors.New("cron entry not found")
+
+// ErrExists is returned when registering a name that is already taken.
+var ErrExists = errors.New("cron entry already exists")
+
+// EnqueueFunc enqueues one instance of a periodic job. The template is an
+// OJS enqueue request body; the returned ID is the created job's ID.
+type EnqueueFunc func(ctx context.Context, template json.RawMessage) (string, error)
+
+// Entry is a registered periodic job. Expression, Timezone, Limit and Paused
+// mirror the OJS CronPolicy.
+type Entry struct {
+	Name       string          `json:"name"`
+	Expression string          `json:"expression"`
+	Timezone   string          `json:"timezone,omitempty"`
+	Limit      int             `json:"limit,omitempty"` // max number of firings, 0 = unlimited
+	Paused     bool            `json:"paused"`
+	Job        json.RawMessage `json:"job"`
+	RunCount   int             `json:"run_count"`
+	NextRunAt  *time.Time      `json:"next_run_at,omitempty"`
+	LastRunAt  *time.Time      `json:"last_run_at,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+
+	schedule *Schedule
+	loc      *time.Location
+}
+
+// Registry holds periodic jobs and enqueues them when they are due.
+type Registry struct {
+	mu          sync.RWMutex
+	entries     map[string]*Entry
+	enqueue     EnqueueFunc
+	store       history.Store
+	broadcaster *sse.Broadcaster
+	cancel      context.CancelFunc
+}
+
+// NewRegistry creates a registry and starts its scheduler. Call Stop to end it.
+func NewRegistry(enqueue EnqueueFunc, store history.Store, broadcaster *sse.Broadcaster) *Registry {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Registry{
+		entries:     make(map[string]*Entry),
+		enqueue:     enqueue,
+		store:       store,
+		broadcaster: broadcaster,
+		cancel:      cancel,
+	}
+	go r.run(ctx)
+	return r
+}
+
+// Stop stops the scheduler.
+func (r *Registry) Stop() {
+	r.cancel()
+}
+
+// Register validates and adds a new entry.
+func (r *Registry) Register(e *Entry) error {
+	if e.Name == "" {
+		return fmt.Errorf("field 'name' is required")
+	}
+	schedule, err := Parse(e.Expression)
+	if err != nil {
+		return fmt.Errorf("invalid expression: %w", err)
+	}
+	loc := time.UTC
+	if e.Timezone != "" {
+		if loc, err = time.LoadLocation(e.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone: %s", e.Timezone)
+		}
+	}
+	if e.Limit < 0 {
+		return fmt.Errorf("field 'limit' must not be negative")
+	}
+	var tmpl struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(e.Job, &tmpl); err != nil || tmpl.Type == "" {
+		return fmt.Errorf("field 'job' must be a job with a 'type'")
+	}
+
+	e.schedule = schedule
+	e.loc = loc
+	e.RunCount = 0
+	e.LastRunAt = nil
+	e.CreatedAt = time.Now().UTC()
+	e.scheduleNext(time.Now())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[e.Name]; ok {
+		return ErrExists
+	}
+	r.entries[e.Name] = e
+	return nil
+}
+
+// Get returns a copy of the named entry.
+func (r *Registry) Get(name string) (Entry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.entries[name]
+	if !ok {
+		return Entry{}, false
+	}
+	return *e, true
+}
+
+// List returns copies of all entries sorted by name.
+func (r *Registry) List() []Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// Delete removes the named entry.
+func (r *Registry) Delete(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[name]; !ok {
+		return ErrNotFound
+	}
+	delete(r.entries, name)
+	return nil
+}
+
+// SetPaused pauses or resumes the named entry. Resuming schedules the next
+// run from now rather than catching up on missed runs.
+func (r *Registry) SetPaused(name string, paused bool) (Entry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[name]
+	if !ok {
+		return Entry{}, ErrNotFound
+	}
+	if e.Paused && !paused {
+		e.Paused = false
+		e.scheduleNext(time.Now())
+	}
+	e.Paused = paused
+	return *e, nil
+}
+
+// scheduleNext sets NextRunAt to the next run after t, or clears it when
+// the entry has reached its limit.
+func (e *Entry) scheduleNext(t time.Time) {
+	e.NextRunAt = nil
+	if e.Limit > 0 && e.RunCount >= e.Limit {
+		return
+	}
+	next := e.schedule.Next(t.In(e.loc))
+	if next.IsZero() {
+		return
+	}
+	next = next.UTC()
+	e.NextRunAt = &next
+}
+
+func (r *Registry) run(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.tick(ctx, now)
+		}
+	}
+}
+
+// firing is a due entry captured under the lock.
+type firing struct {
+	name string
+	job  json.RawMessage
+	at   time.Time
+}
+
+// tick enqueues every entry that is due at now.
+func (r *Registry) tick(ctx context.Context, now time.Time) {
+	r.mu.Lock()
+	var due []firing
+	for _, e := range r.entries {
+		if e.Paused || e.NextRunAt == nil || e.NextRunAt.After(now) {
+			continue
+		}
+		due = append(due, firing{name: e.Name, job: e.Job, at: *e.NextRunAt})
+		e.RunCount++
+		last := now.UTC()
+		e.LastRunAt = &last
+		e.scheduleNext(now)
+	}
+	r.mu.Unlock()
+
+	for _, f := range due {
+		r.fire(ctx, f)
+	}
+}
+
+func (r *Registry) fire(ctx context.Context, f firing) {
+	jobID, err := r.enqueue(ctx, f.job)
+	firedAt := time.Now()
+
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+		slog.Warn("cron enqueue failed", "name", f.name, "err", err)
+	}
+
+	if r.store != nil {
+		firingRecord := &history.CronFiring{
+			Name:    f.name,
+			JobID:   jobID,
+			FiredAt: firedAt,
+			Error:   errMsg,
+		}
+		if err := r.store.SaveCronFiring(ctx, firingRecord); err != nil {
+			slog.Warn("failed to save cron firing to history", "err", err)
+		}
+	}
+
+	if r.broadcaster != nil {
+		r.broadcaster.Broadcast(sse.Event{
+			Type:      sse.EventCronFired,
+			Timestamp: firedAt,
+			JobID:     jobID,
+			Data: map[string]any{
+				"name":         f.name,
+				"job_id":       jobID,
+				"scheduled_at": f.at.UTC().Format(time.RFC3339),
+				"error":        errMsg,
+			},
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	mu   sync.Mutex
+	jobs []string
+}
+
+func (rec *recorder) enqueue(ctx context.Context, template json.RawMessage) (string, error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.jobs = append(rec.jobs, string(template))
+	return "job-1", nil
+}
+
+func (rec *recorder) count() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return len(rec.jobs)
+}
+
+func newTestRegistry(t *testing.T) (*Registry, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	r := NewRegistry(rec.enqueue, nil, nil)
+	r.Stop() // drive the scheduler by hand
+	return r, rec
+}
+
+func testEntry(name string) *Entry {
+	return &Entry{
+		Name:       name,
+		Expression: "* * * * *",
+		Job:        json.RawMessage(`{"type":"cron.tick","args":[]}`),
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	r, _ := newTestRegistry(t)
+
+	cases := map[string]*Entry{
+		"missing name":   {Expression: "* * * * *", Job: json.RawMessage(`{"type":"a"}`)},
+		"bad expression": {Name: "a", Expression: "nope", Job: json.RawMessage(`{"type":"a"}`)},
+		"bad timezone":   {Name: "a", Expression: "* * * * *", Timezone: "Mars/Olympus", Job: json.RawMessage(`{"type":"a"}`)},
+		"missing type":   {Name: "a", Expression: "* * * * *", Job: json.RawMessage(`{}`)},
+	}
+	for name, e := range cases {
+		if err := r.Register(e); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if err := r.Register(testEntry("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(testEntry("a")); err != ErrExists {
+		t.Errorf("expected ErrExists, got %v", err)
+	}
+}
+
+func TestTickFiresDueEntries(t *testing.T) {
+	r, rec := newTestRegistry(t)
+	ctx := context.Background()
+
+	if err := r.Register(testEntry("every-minute")); err != nil {
+		t.Fatal(err)
+	}
+	e, _ := r.Get("every-minute")
+
+	r.tick(ctx, e.NextRunAt.Add(-time.Second))
+	if rec.count() != 0 {
+		t.Fatalf("expected no firing before next_run_at, got %d", rec.count())
+	}
+
+	r.tick(ctx, *e.NextRunAt)
+	if rec.count() != 1 {
+		t.Fatalf("expected 1 firing, got %d", rec.count())
+	}
+
+	e, _ = r.Get("every-minute")
+	if e.RunCount != 1 || e.LastRunAt == nil {
+		t.Errorf("expected run_count 1 and last_run_at set, got %d / %v", e.RunCount, e.LastRunAt)
+	}
+}
+
+func TestPausedEntriesDoNotFire(t *testing.T) {
+	r, rec := newTestRegistry(t)
+	ctx := context.Background()
+
+	r.Register(testEntry("paused"))
+	if _, err := r.SetPaused("paused", true); err != nil {
+		t.Fatal(err)
+	}
+
+	r.tick(ctx, time.Now().Add(time.Hour))
+	if rec.count() != 0 {
+		t.Errorf("expected no firing while paused, got %d", rec.count())
+	}
+
+	e, _ := r.SetPaused("paused", false)
+	r.tick(ctx, *e.NextRunAt)
+	if rec.count() != 1 {
+		t.Errorf("expected 1 firing after resume, got %d", rec.count())
+	}
+}
+
+func TestLimitStopsFiring(t *testing.T) {
+	r, rec := newTestRegistry(t)
+	ctx := context.Background()
+
+	e := testEntry("limited")
+	e.Limit = 2
+	r.Register(e)
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Minute)
+		r.tick(ctx, now)
+	}
+
+	if rec.count() != 2 {
+		t.Errorf("expected 2 firings, got %d", rec.count())
+	}
+	if got, _ := r.Get("limited"); got.NextRunAt != nil {
+		t.Errorf("expected no next_run_at after limit, got %v", got.NextRunAt)
+	}
+}
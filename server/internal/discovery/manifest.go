@@ -8,12 +8,14 @@ import (
 	"time"
 )
 
-// Manifest represents an OJS worker's manifest response.
+// Manifest represents an OJS worker's manifest response. Schemas maps job
+// types to the JSON Schema their args must match.
 type Manifest struct {
-	Name     string   `json:"name"`
-	Version  string   `json:"version,omitempty"`
-	Queues   []string `json:"queues,omitempty"`
-	JobTypes []string `json:"job_types,omitempty"`
+	Name     string                     `json:"name"`
+	Version  string                     `json:"version,omitempty"`
+	Queues   []string                   `json:"queues,omitempty"`
+	JobTypes []string                   `json:"job_types,omitempty"`
+	Schemas  map[string]json.RawMessage `json:"schemas,omitempty"`
 }
 
 // FetchManifest retrieves a worker's manifest from the given URL.
@@ -1,6 +1,8 @@
 package discovery
 
 import (
+	"bytes"
+	"encoding/json"
 	"sync"
 	"time"
 
@@ -17,22 +19,29 @@ const (
 
 // DiscoveredWorker holds information about a discovered OJS worker.
 type DiscoveredWorker struct {
-	ID           string       `json:"id"`
-	Name         string       `json:"name"`
-	URL          string       `json:"url"`
-	Port         int          `json:"port"`
-	Status       WorkerStatus `json:"status"`
-	Queues       []string     `json:"queues,omitempty"`
-	JobTypes     []string     `json:"job_types,omitempty"`
-	LastSeen     time.Time    `json:"last_seen"`
-	FailCount    int          `json:"-"`
+	ID        string                     `json:"id"`
+	Name      string                     `json:"name"`
+	URL       string                     `json:"url"`
+	Port      int                        `json:"port"`
+	Status    WorkerStatus               `json:"status"`
+	Queues    []string                   `json:"queues,omitempty"`
+	JobTypes  []string                   `json:"job_types,omitempty"`
+	Schemas   map[string]json.RawMessage `json:"schemas,omitempty"`
+	LastSeen  time.Time                  `json:"last_seen"`
+	FailCount int                        `json:"-"`
 }
 
+// RegisterCallback is called when a worker is first registered, and again
+// when it is rediscovered advertising new or changed schemas. Schemas then
+// holds only those.
+type RegisterCallback func(worker *DiscoveredWorker)
+
 // Registry manages discovered workers.
 type Registry struct {
 	mu          sync.RWMutex
 	workers     map[string]*DiscoveredWorker
 	broadcaster *sse.Broadcaster
+	onRegister  RegisterCallback
 }
 
 // NewRegistry creates a new worker registry.
@@ -43,21 +52,46 @@ func NewRegistry(broadcaster *sse.Broadcaster) *Registry {
 	}
 }
 
-// Register adds or updates a worker in the registry.
+// SetRegisterCallback sets the function called when a new worker is
+// registered or a known one advertises new schemas.
+func (r *Registry) SetRegisterCallback(fn RegisterCallback) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onRegister = fn
+}
+
+// Register adds or updates a worker in the registry. A known worker takes
+// on the queues, job types and schemas it now advertises.
 func (r *Registry) Register(worker *DiscoveredWorker) {
 	r.mu.Lock()
-	existing, isNew := r.workers[worker.ID]
-	if !isNew || existing == nil {
+	existing, ok := r.workers[worker.ID]
+	registered := worker
+	if !ok || existing == nil {
 		worker.Status = WorkerConnected
 		worker.LastSeen = time.Now()
 		r.workers[worker.ID] = worker
 	} else {
+		changed := changedSchemas(existing.Schemas, worker.Schemas)
 		existing.Status = WorkerConnected
 		existing.LastSeen = time.Now()
 		existing.FailCount = 0
+		existing.Queues = worker.Queues
+		existing.JobTypes = worker.JobTypes
+		existing.Schemas = worker.Schemas
+		registered = nil
+		if len(changed) > 0 {
+			w := *existing
+			w.Schemas = changed
+			registered = &w
+		}
 	}
+	onRegister := r.onRegister
 	r.mu.Unlock()
 
+	if registered != nil && onRegister != nil {
+		onRegister(registered)
+	}
+
 	if r.broadcaster != nil {
 		r.broadcaster.Broadcast(sse.Event{
 			Type:      sse.EventWorkerConnected,
@@ -71,6 +105,18 @@ func (r *Registry) Register(worker *DiscoveredWorker) {
 	}
 }
 
+// changedSchemas returns the schemas in next that are not in prev, or
+// differ from it.
+func changedSchemas(prev, next map[string]json.RawMessage) map[string]json.RawMessage {
+	changed := make(map[string]json.RawMessage)
+	for jobType, raw := range next {
+		if old, ok := prev[jobType]; !ok || !bytes.Equal(old, raw) {
+			changed[jobType] = raw
+		}
+	}
+	return changed
+}
+
 // Unregister removes a worker from the registry.
 func (r *Registry) Unregister(id string) {
 	r.mu.Lock()
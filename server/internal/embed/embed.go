@@ -1,9 +1,22 @@
 package embed
 
-import "embed"
+import (
+	"embed"
+	"io/fs"
+)
 
 // Dist contains the built SPA files from ui/dist/.
 // The build script copies ui/dist/ into this directory before go build.
 //
 //go:embed all:dist
 var Dist embed.FS
+
+// Schemas returns the bundled OJS JSON Schemas, which the UI ships in
+// public/schema/.
+func Schemas() fs.FS {
+	schemas, err := fs.Sub(Dist, "dist/schema")
+	if err != nil {
+		panic("embedded dist/schema/ not found: " + err.Error())
+	}
+	return schemas
+}
@@ -52,6 +52,40 @@ var migrations = []struct {
 			CREATE INDEX IF NOT EXISTS idx_job_state_history_job_id ON job_state_history(job_id);
 		`,
 	},
+	{
+		name: "003_create_cron_firings",
+		sql: `
+			CREATE TABLE IF NOT EXISTS cron_firings (
+				id       INTEGER PRIMARY KEY AUTOINCREMENT,
+				name     TEXT NOT NULL,
+				job_id   TEXT NOT NULL DEFAULT '',
+				fired_at DATETIME NOT NULL DEFAULT (datetime('now')),
+				error    TEXT NOT NULL DEFAULT ''
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_cron_firings_name ON cron_firings(name);
+		`,
+	},
+	{
+		name: "004_add_playground_jobs_progress",
+		sql: `
+			ALTER TABLE playground_jobs ADD COLUMN progress TEXT;
+		`,
+	},
+	{
+		name: "005_create_args_schemas",
+		sql: `
+			CREATE TABLE IF NOT EXISTS args_schemas (
+				uri        TEXT PRIMARY KEY,
+				job_type   TEXT NOT NULL,
+				schema     TEXT NOT NULL,
+				source     TEXT NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL DEFAULT (datetime('now'))
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_args_schemas_job_type ON args_schemas(job_type);
+		`,
+	},
 }
 
 // RunMigrations applies all pending migrations.
@@ -45,7 +45,7 @@ func (s *SQLiteStore) SaveJob(ctx context.Context, job *Job) error {
 		meta = string(job.Meta)
 	}
 
-	var result, errStr *string
+	var result, errStr, progress *string
 	if job.Result != nil {
 		s := string(job.Result)
 		result = &s
@@ -54,22 +54,27 @@ func (s *SQLiteStore) SaveJob(ctx context.Context, job *Job) error {
 		s := string(job.Error)
 		errStr = &s
 	}
+	if job.Progress != nil {
+		s := string(job.Progress)
+		progress = &s
+	}
 
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO playground_jobs (id, type, state, queue, args, meta, priority, attempt, max_attempts, created_at, updated_at, backend, result, error)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO playground_jobs (id, type, state, queue, args, meta, priority, attempt, max_attempts, created_at, updated_at, backend, result, error, progress)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			state = excluded.state,
 			attempt = excluded.attempt,
 			updated_at = excluded.updated_at,
 			result = excluded.result,
-			error = excluded.error
+			error = excluded.error,
+			progress = COALESCE(excluded.progress, progress)
 	`,
 		job.ID, job.Type, job.State, job.Queue, args, meta,
 		job.Priority, job.Attempt, job.MaxAttempts,
 		job.CreatedAt.UTC().Format(time.RFC3339),
 		job.UpdatedAt.UTC().Format(time.RFC3339),
-		job.Backend, result, errStr,
+		job.Backend, result, errStr, progress,
 	)
 	return err
 }
@@ -101,9 +106,17 @@ func (s *SQLiteStore) UpdateJobState(ctx context.Context, jobID, fromState, toSt
 	return tx.Commit()
 }
 
+func (s *SQLiteStore) UpdateJobProgress(ctx context.Context, jobID string, progress json.RawMessage) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE playground_jobs SET progress = ?, updated_at = datetime('now') WHERE id = ?",
+		string(progress), jobID,
+	)
+	return err
+}
+
 func (s *SQLiteStore) GetJob(ctx context.Context, jobID string) (*Job, error) {
 	row := s.db.QueryRowContext(ctx, `
-		SELECT id, type, state, queue, args, meta, priority, attempt, max_attempts, created_at, updated_at, backend, result, error
+		SELECT id, type, state, queue, args, meta, priority, attempt, max_attempts, created_at, updated_at, backend, result, error, progress
 		FROM playground_jobs WHERE id = ?
 	`, jobID)
 
@@ -141,7 +154,7 @@ func (s *SQLiteStore) ListJobs(ctx context.Context, filter ListFilter) ([]*Job,
 	if limit <= 0 {
 		limit = 50
 	}
-	query := fmt.Sprintf("SELECT id, type, state, queue, args, meta, priority, attempt, max_attempts, created_at, updated_at, backend, result, error FROM playground_jobs WHERE %s ORDER BY created_at DESC LIMIT ? OFFSET ?", where)
+	query := fmt.Sprintf("SELECT id, type, state, queue, args, meta, priority, attempt, max_attempts, created_at, updated_at, backend, result, error, progress FROM playground_jobs WHERE %s ORDER BY created_at DESC LIMIT ? OFFSET ?", where)
 	args = append(args, limit, filter.Offset)
 
 	rows, err := s.db.QueryContext(ctx, query, args...)
@@ -186,6 +199,90 @@ func (s *SQLiteStore) GetJobHistory(ctx context.Context, jobID string) ([]StateC
 	return changes, rows.Err()
 }
 
+func (s *SQLiteStore) SaveCronFiring(ctx context.Context, firing *CronFiring) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO cron_firings (name, job_id, fired_at, error) VALUES (?, ?, ?, ?)",
+		firing.Name, firing.JobID, firing.FiredAt.UTC().Format(time.RFC3339), firing.Error,
+	)
+	return err
+}
+
+func (s *SQLiteStore) ListCronFirings(ctx context.Context, name string, limit int) ([]CronFiring, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT name, job_id, fired_at, error FROM cron_firings WHERE name = ? ORDER BY id DESC LIMIT ?",
+		name, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var firings []CronFiring
+	for rows.Next() {
+		var f CronFiring
+		var firedAt string
+		if err := rows.Scan(&f.Name, &f.JobID, &firedAt, &f.Error); err != nil {
+			return nil, err
+		}
+		f.FiredAt, _ = time.Parse(time.RFC3339, firedAt)
+		firings = append(firings, f)
+	}
+
+	return firings, rows.Err()
+}
+
+// sortableTime is a fixed-width RFC 3339 layout, so that stored times
+// sort as strings in the order they happened.
+const sortableTime = "2006-01-02T15:04:05.000000000Z07:00"
+
+func (s *SQLiteStore) SaveArgsSchema(ctx context.Context, schema *ArgsSchema) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO args_schemas (uri, job_type, schema, source, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(uri) DO UPDATE SET
+			job_type = excluded.job_type,
+			schema = excluded.schema,
+			source = excluded.source,
+			created_at = excluded.created_at
+	`,
+		schema.URI, schema.JobType, string(schema.Schema), schema.Source,
+		schema.CreatedAt.UTC().Format(sortableTime),
+	)
+	return err
+}
+
+func (s *SQLiteStore) ListArgsSchemas(ctx context.Context) ([]ArgsSchema, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT uri, job_type, schema, source, created_at FROM args_schemas ORDER BY created_at, uri",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schemas []ArgsSchema
+	for rows.Next() {
+		var a ArgsSchema
+		var schema, createdAt string
+		if err := rows.Scan(&a.URI, &a.JobType, &schema, &a.Source, &createdAt); err != nil {
+			return nil, err
+		}
+		a.Schema = json.RawMessage(schema)
+		a.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		schemas = append(schemas, a)
+	}
+
+	return schemas, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteArgsSchema(ctx context.Context, uri string) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM args_schemas WHERE uri = ?", uri)
+	return err
+}
+
 func (s *SQLiteStore) Close() error {
 	return s.db.Close()
 }
@@ -198,11 +295,11 @@ type scanner interface {
 func scanJob(row *sql.Row) (*Job, error) {
 	var job Job
 	var args, meta, createdAt, updatedAt string
-	var result, errStr *string
+	var result, errStr, progress *string
 
 	err := row.Scan(&job.ID, &job.Type, &job.State, &job.Queue, &args, &meta,
 		&job.Priority, &job.Attempt, &job.MaxAttempts,
-		&createdAt, &updatedAt, &job.Backend, &result, &errStr)
+		&createdAt, &updatedAt, &job.Backend, &result, &errStr, &progress)
 	if err != nil {
 		return nil, err
 	}
@@ -217,6 +314,9 @@ func scanJob(row *sql.Row) (*Job, error) {
 	if errStr != nil {
 		job.Error = json.RawMessage(*errStr)
 	}
+	if progress != nil {
+		job.Progress = json.RawMessage(*progress)
+	}
 
 	return &job, nil
 }
@@ -224,11 +324,11 @@ func scanJob(row *sql.Row) (*Job, error) {
 func scanJobRows(rows *sql.Rows) (*Job, error) {
 	var job Job
 	var args, meta, createdAt, updatedAt string
-	var result, errStr *string
+	var result, errStr, progress *string
 
 	err := rows.Scan(&job.ID, &job.Type, &job.State, &job.Queue, &args, &meta,
 		&job.Priority, &job.Attempt, &job.MaxAttempts,
-		&createdAt, &updatedAt, &job.Backend, &result, &errStr)
+		&createdAt, &updatedAt, &job.Backend, &result, &errStr, &progress)
 	if err != nil {
 		return nil, err
 	}
@@ -243,6 +343,9 @@ func scanJobRows(rows *sql.Rows) (*Job, error) {
 	if errStr != nil {
 		job.Error = json.RawMessage(*errStr)
 	}
+	if progress != nil {
+		job.Progress = json.RawMessage(*progress)
+	}
 
 	return &job, nil
 }
@@ -130,6 +130,30 @@ func TestUpdateJobState(t *testing.T) {
 	}
 }
 
+func TestUpdateJobProgress(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	store.SaveJob(ctx, testJob("job-010"))
+	progress := json.RawMessage(`{"value":0.5,"checkpoint":{"page":3}}`)
+	if err := store.UpdateJobProgress(ctx, "job-010", progress); err != nil {
+		t.Fatal(err)
+	}
+
+	// A later state change must not clear the progress.
+	job := testJob("job-010")
+	job.State = "completed"
+	store.SaveJob(ctx, job)
+
+	got, err := store.GetJob(ctx, "job-010")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Progress) != string(progress) {
+		t.Errorf("expected progress %s, got %s", progress, got.Progress)
+	}
+}
+
 func TestGetJobHistory(t *testing.T) {
 	store := newTestStore(t)
 	ctx := context.Background()
@@ -293,3 +317,68 @@ func TestTempDirCleanup(t *testing.T) {
 		t.Error("temp dir should still exist during test")
 	}
 }
+
+func TestCronFirings(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	store.SaveCronFiring(ctx, &CronFiring{Name: "nightly", JobID: "job-1", FiredAt: now})
+	store.SaveCronFiring(ctx, &CronFiring{Name: "nightly", Error: "backend down", FiredAt: now.Add(time.Minute)})
+	store.SaveCronFiring(ctx, &CronFiring{Name: "hourly", JobID: "job-2", FiredAt: now})
+
+	firings, err := store.ListCronFirings(ctx, "nightly", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(firings) != 2 {
+		t.Fatalf("expected 2 firings, got %d", len(firings))
+	}
+	if firings[0].Error != "backend down" {
+		t.Errorf("expected newest firing first, got %+v", firings[0])
+	}
+	if !firings[1].FiredAt.Equal(now) {
+		t.Errorf("expected fired_at %s, got %s", now, firings[1].FiredAt)
+	}
+}
+
+func TestArgsSchemas(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	v1 := &ArgsSchema{URI: "urn:ojs:schema:email.send:v1", JobType: "email.send", Schema: json.RawMessage(`{"type":"array"}`), CreatedAt: now}
+	v2 := &ArgsSchema{URI: "urn:ojs:schema:email.send:v2", JobType: "email.send", Schema: json.RawMessage(`{"type":"array"}`), Source: "worker:mailer", CreatedAt: now.Add(time.Second)}
+	for _, s := range []*ArgsSchema{v2, v1} {
+		if err := store.SaveArgsSchema(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v1.Schema = json.RawMessage(`{"type":"array","maxItems":1}`)
+	if err := store.SaveArgsSchema(ctx, v1); err != nil {
+		t.Fatal(err)
+	}
+
+	schemas, err := store.ListArgsSchemas(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 2 {
+		t.Fatalf("expected 2 schemas, got %d", len(schemas))
+	}
+	if schemas[0].URI != v1.URI || string(schemas[0].Schema) != string(v1.Schema) {
+		t.Errorf("expected the updated v1 schema first, got %+v", schemas[0])
+	}
+	if schemas[1].Source != "worker:mailer" || !schemas[1].CreatedAt.Equal(v2.CreatedAt) {
+		t.Errorf("expected v2 to round-trip, got %+v", schemas[1])
+	}
+
+	if err := store.DeleteArgsSchema(ctx, v1.URI); err != nil {
+		t.Fatal(err)
+	}
+	schemas, _ = store.ListArgsSchemas(ctx)
+	if len(schemas) != 1 || schemas[0].URI != v2.URI {
+		t.Errorf("expected only v2 after delete, got %+v", schemas)
+	}
+}
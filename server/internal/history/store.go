@@ -22,6 +22,7 @@ type Job struct {
 	Backend     string          `json:"backend"`
 	Result      json.RawMessage `json:"result,omitempty"`
 	Error       json.RawMessage `json:"error,omitempty"`
+	Progress    json.RawMessage `json:"progress,omitempty"`
 }
 
 // StateChange represents a single state transition in a job's history.
@@ -32,6 +33,23 @@ type StateChange struct {
 	Reason    string    `json:"reason,omitempty"`
 }
 
+// CronFiring records one firing of a periodic job.
+type CronFiring struct {
+	Name    string    `json:"name"`
+	JobID   string    `json:"job_id,omitempty"`
+	FiredAt time.Time `json:"fired_at"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// ArgsSchema is a registered JSON Schema for the args of a job type.
+type ArgsSchema struct {
+	URI       string          `json:"uri"`
+	JobType   string          `json:"job_type"`
+	Schema    json.RawMessage `json:"schema"`
+	Source    string          `json:"source,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
 // ListFilter specifies filters for listing jobs.
 type ListFilter struct {
 	State  string
@@ -45,8 +63,14 @@ type ListFilter struct {
 type Store interface {
 	SaveJob(ctx context.Context, job *Job) error
 	UpdateJobState(ctx context.Context, jobID, fromState, toState, reason string) error
+	UpdateJobProgress(ctx context.Context, jobID string, progress json.RawMessage) error
 	GetJob(ctx context.Context, jobID string) (*Job, error)
 	ListJobs(ctx context.Context, filter ListFilter) ([]*Job, int, error)
 	GetJobHistory(ctx context.Context, jobID string) ([]StateChange, error)
+	SaveCronFiring(ctx context.Context, firing *CronFiring) error
+	ListCronFirings(ctx context.Context, name string, limit int) ([]CronFiring, error)
+	SaveArgsSchema(ctx context.Context, schema *ArgsSchema) error
+	ListArgsSchemas(ctx context.Context) ([]ArgsSchema, error)
+	DeleteArgsSchema(ctx context.Context, uri string) error
 	Close() error
 }
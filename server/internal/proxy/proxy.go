@@ -43,6 +43,7 @@ func NewProxy(targetURL string, store history.Store, broadcaster *sse.Broadcaste
 		Director: func(req *http.Request) {
 			req.URL.Scheme = target.Scheme
 			req.URL.Host = target.Host
+			req.URL.Path = strings.TrimSuffix(target.Path, "/") + req.URL.Path
 			req.Host = target.Host
 		},
 		ModifyResponse: p.modifyResponse,
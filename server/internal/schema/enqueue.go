@@ -0,0 +1,180 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// envelopeOptions are the enqueue options that are attributes of the job
+// envelope itself.
+var envelopeOptions = map[string]bool{
+	"queue":        true,
+	"priority":     true,
+	"timeout":      true,
+	"scheduled_at": true,
+	"expires_at":   true,
+	"retry":        true,
+	"unique":       true,
+}
+
+// defaulted are envelope attributes the server fills in when an enqueue
+// request leaves them out.
+var defaulted = map[string]bool{
+	"specversion": true,
+	"id":          true,
+	"queue":       true,
+	"args":        true,
+}
+
+// ValidateEnqueue checks an OJS enqueue request, the body of POST /jobs,
+// against the job schema. The request is checked as the envelope it
+// describes; violations are reported at their path in the request.
+func (v *Validator) ValidateEnqueue(data []byte) ([]Error, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req, ok := doc.(map[string]any)
+	if !ok {
+		return []Error{{Path: "/", Message: "must be an object", Keyword: "type"}}, nil
+	}
+
+	env := make(map[string]any, len(req))
+	for k, val := range req {
+		if k != "options" {
+			env[k] = val
+		}
+	}
+	fromOptions := make(map[string]bool)
+	if opts, ok := req["options"].(map[string]any); ok {
+		for k, val := range opts {
+			if !envelopeOptions[k] {
+				continue
+			}
+			if _, isObject := val.(map[string]any); k == "timeout" && isObject {
+				// The timeout policy object is an extension the job
+				// schema does not describe.
+				continue
+			}
+			env[k] = val
+			fromOptions[k] = true
+		}
+	}
+
+	var errs []Error
+	for _, e := range validate(v.schemas[Job], env) {
+		attr, nested, _ := strings.Cut(strings.TrimPrefix(e.Path, "/"), "/")
+		if e.Keyword == "required" && nested == "" && defaulted[attr] {
+			continue
+		}
+		if fromOptions[attr] {
+			e.Path = "/options" + e.Path
+		}
+		errs = append(errs, e)
+	}
+	return errs, nil
+}
+
+// ValidateBatch checks each job of a batch enqueue request, the body of
+// POST /jobs/batch, as ValidateEnqueue does. Violations are reported at
+// their path in the batch, such as /jobs/0/type.
+func (v *Validator) ValidateBatch(data []byte) ([]Error, error) {
+	var req struct {
+		Jobs []json.RawMessage `json:"jobs"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+
+	var errs []Error
+	for i, job := range req.Jobs {
+		jobErrs, err := v.ValidateEnqueue(job)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range jobErrs {
+			e.Path = fmt.Sprintf("/jobs/%d%s", i, strings.TrimSuffix(e.Path, "/"))
+			errs = append(errs, e)
+		}
+	}
+	return errs, nil
+}
+
+// Middleware rejects POST .../jobs and .../jobs/batch requests whose body
+// is not a valid OJS job, or holds one that is not, with 422 and a
+// validation_error listing each invalid field. Bodies that are not JSON
+// are passed on for the backend to report.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validate, what := v.ValidateEnqueue, "job"
+		switch {
+		case r.Method != http.MethodPost:
+			next.ServeHTTP(w, r)
+			return
+		case strings.HasSuffix(r.URL.Path, "/jobs"):
+		case strings.HasSuffix(r.URL.Path, "/jobs/batch"):
+			validate, what = v.ValidateBatch, "batch"
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "Failed to read request body: "+err.Error(), nil)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		errs, err := validate(body)
+		if err != nil || len(errs) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		type detail struct {
+			Path    string `json:"path"`
+			Message string `json:"message"`
+		}
+		details := make([]detail, len(errs))
+		msgs := make([]string, len(errs))
+		for i, e := range errs {
+			details[i] = detail{Path: FieldPath(e.Path), Message: e.Message}
+			msgs[i] = details[i].Path + " " + e.Message
+		}
+		writeError(w, http.StatusUnprocessableEntity, "validation_error",
+			"Invalid "+what+": "+strings.Join(msgs, "; ")+".", details)
+	})
+}
+
+// FieldPath converts a JSON Pointer to the dotted field path OJS
+// validation errors use, such as "options.retry.max_attempts".
+func FieldPath(ptr string) string {
+	if ptr == "/" {
+		return ""
+	}
+	toks := strings.Split(strings.TrimPrefix(ptr, "/"), "/")
+	for i, tok := range toks {
+		tok = strings.ReplaceAll(tok, "~1", "/")
+		toks[i] = strings.ReplaceAll(tok, "~0", "~")
+	}
+	return strings.Join(toks, ".")
+}
+
+// writeError writes an OJS error response.
+func writeError(w http.ResponseWriter, status int, code, message string, details any) {
+	body := map[string]any{"code": code, "message": message}
+	if details != nil {
+		body["details"] = details
+	}
+	w.Header().Set("Content-Type", "application/openjobspec+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": body})
+}
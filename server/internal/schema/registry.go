@@ -0,0 +1,215 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/openjobspec/ojs-playground/server/internal/history"
+)
+
+// ErrSchemaNotFound is returned for operations on an unregistered args
+// schema.
+var ErrSchemaNotFound = errors.New("args schema not found")
+
+// ArgsSchema is a JSON Schema for the args of one job type. URI identifies
+// it, and is what a job's schema attribute refers to.
+type ArgsSchema struct {
+	URI       string          `json:"uri"`
+	JobType   string          `json:"job_type"`
+	Schema    json.RawMessage `json:"schema"`
+	Source    string          `json:"source,omitempty"` // "api" or "worker:<name>"
+	CreatedAt time.Time       `json:"created_at"`
+
+	compiled *jsonschema.Schema
+}
+
+// Registry holds the args schemas of job types and persists them in the
+// history store.
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string]*ArgsSchema // URI → schema
+	store   history.Store
+}
+
+// NewRegistry creates a registry holding the schemas saved in store.
+// Saved schemas that no longer compile are skipped.
+func NewRegistry(ctx context.Context, store history.Store) (*Registry, error) {
+	r := &Registry{schemas: make(map[string]*ArgsSchema), store: store}
+	if store == nil {
+		return r, nil
+	}
+
+	saved, err := store.ListArgsSchemas(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load args schemas: %w", err)
+	}
+	for _, h := range saved {
+		s := &ArgsSchema{URI: h.URI, JobType: h.JobType, Schema: h.Schema, Source: h.Source, CreatedAt: h.CreatedAt}
+		if s.compiled, err = compileArgs(s.Schema); err != nil {
+			slog.Warn("skipping invalid args schema", "uri", s.URI, "err", err)
+			continue
+		}
+		r.schemas[s.URI] = s
+	}
+	return r, nil
+}
+
+// DefaultURI is the schema URI used for a job type when none is given.
+func DefaultURI(jobType string) string {
+	return "urn:ojs:schema:" + jobType + ":v1"
+}
+
+// Register compiles and adds s, replacing any schema with the same URI.
+// Without a URI, the schema's $id is used, or else DefaultURI.
+func (r *Registry) Register(ctx context.Context, s *ArgsSchema) error {
+	if s.JobType == "" {
+		return fmt.Errorf("field 'job_type' is required")
+	}
+	if len(s.Schema) == 0 {
+		return fmt.Errorf("field 'schema' is required")
+	}
+	compiled, err := compileArgs(s.Schema)
+	if err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	if s.URI == "" {
+		var doc struct {
+			ID string `json:"$id"`
+		}
+		json.Unmarshal(s.Schema, &doc)
+		s.URI = doc.ID
+	}
+	if s.URI == "" {
+		s.URI = DefaultURI(s.JobType)
+	}
+	s.compiled = compiled
+	s.CreatedAt = time.Now().UTC()
+
+	if r.store != nil {
+		err := r.store.SaveArgsSchema(ctx, &history.ArgsSchema{
+			URI:       s.URI,
+			JobType:   s.JobType,
+			Schema:    s.Schema,
+			Source:    s.Source,
+			CreatedAt: s.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("save args schema: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[s.URI] = s
+	return nil
+}
+
+// Get returns a copy of the schema with the given URI.
+func (r *Registry) Get(uri string) (ArgsSchema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.schemas[uri]
+	if !ok {
+		return ArgsSchema{}, false
+	}
+	return *s, true
+}
+
+// List returns copies of the schemas for jobType, or of all schemas if
+// jobType is empty, sorted by job type and then newest first.
+func (r *Registry) List(jobType string) []ArgsSchema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schemas := make([]ArgsSchema, 0, len(r.schemas))
+	for _, s := range r.schemas {
+		if jobType == "" || s.JobType == jobType {
+			schemas = append(schemas, *s)
+		}
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		if schemas[i].JobType != schemas[j].JobType {
+			return schemas[i].JobType < schemas[j].JobType
+		}
+		return schemas[i].CreatedAt.After(schemas[j].CreatedAt)
+	})
+	return schemas
+}
+
+// Delete removes the schema with the given URI. It is removed from the
+// store first, so a schema that fails to delete is still enforced.
+func (r *Registry) Delete(ctx context.Context, uri string) error {
+	r.mu.RLock()
+	_, ok := r.schemas[uri]
+	r.mu.RUnlock()
+	if !ok {
+		return ErrSchemaNotFound
+	}
+
+	if r.store != nil {
+		if err := r.store.DeleteArgsSchema(ctx, uri); err != nil {
+			return fmt.Errorf("delete args schema: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.schemas, uri)
+	return nil
+}
+
+// ValidateArgs checks the args of a job against its schema. A job that
+// names a schema URI is checked against that schema; otherwise the most
+// recently registered schema for its type is used. Args with no schema to
+// check against, including those naming an unregistered URI, are valid.
+// Paths are JSON Pointers into args.
+func (r *Registry) ValidateArgs(jobType, uri string, args []byte) []Error {
+	s := r.lookup(jobType, uri)
+	if s == nil {
+		return nil
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(args))
+	if err != nil {
+		return []Error{ParseError(err)}
+	}
+	return validate(s.compiled, doc)
+}
+
+func (r *Registry) lookup(jobType, uri string) *ArgsSchema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if uri != "" {
+		return r.schemas[uri]
+	}
+	var latest *ArgsSchema
+	for _, s := range r.schemas {
+		if s.JobType == jobType && (latest == nil || s.CreatedAt.After(latest.CreatedAt)) {
+			latest = s
+		}
+	}
+	return latest
+}
+
+// compileArgs compiles a standalone args schema.
+func compileArgs(raw json.RawMessage) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	const loc = "file:///args.schema.json"
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	if err := c.AddResource(loc, doc); err != nil {
+		return nil, err
+	}
+	return c.Compile(loc)
+}
@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/openjobspec/ojs-playground/server/internal/history"
+)
+
+func newTestStore(t *testing.T) history.Store {
+	t.Helper()
+	store, err := history.NewSQLiteStore(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+const emailArgs = `{
+	"type": "array",
+	"prefixItems": [{"type": "string", "format": "email"}],
+	"minItems": 1
+}`
+
+func TestRegistryValidateArgs(t *testing.T) {
+	ctx := context.Background()
+	r, err := NewRegistry(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := r.ValidateArgs("email.send", "", []byte(`[1]`)); errs != nil {
+		t.Errorf("expected args of an unregistered type to pass, got %+v", errs)
+	}
+
+	s := &ArgsSchema{JobType: "email.send", Schema: json.RawMessage(emailArgs)}
+	if err := r.Register(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if s.URI != DefaultURI("email.send") {
+		t.Errorf("expected the default URI, got %q", s.URI)
+	}
+
+	if errs := r.ValidateArgs("email.send", "", []byte(`["user@example.com"]`)); len(errs) != 0 {
+		t.Errorf("expected valid args, got %+v", errs)
+	}
+	errs := r.ValidateArgs("email.send", "", []byte(`["not an address"]`))
+	if len(errs) != 1 || errs[0].Path != "/0" {
+		t.Errorf("expected an error at /0, got %+v", errs)
+	}
+	if errs := r.ValidateArgs("email.send", "", []byte(`[]`)); len(errs) != 1 || errs[0].Path != "/" {
+		t.Errorf("expected an error for empty args, got %+v", errs)
+	}
+
+	// A newer version applies to jobs that do not pin a schema.
+	v2 := &ArgsSchema{
+		JobType: "email.send",
+		Schema:  json.RawMessage(`{"$id": "urn:ojs:schema:email.send:v2", "type": "array", "maxItems": 0}`),
+	}
+	if err := r.Register(ctx, v2); err != nil {
+		t.Fatal(err)
+	}
+	if v2.URI != "urn:ojs:schema:email.send:v2" {
+		t.Errorf("expected the $id as URI, got %q", v2.URI)
+	}
+	if errs := r.ValidateArgs("email.send", "", []byte(`["user@example.com"]`)); len(errs) == 0 {
+		t.Error("expected the newest schema to be used")
+	}
+	if errs := r.ValidateArgs("email.send", s.URI, []byte(`["user@example.com"]`)); len(errs) != 0 {
+		t.Errorf("expected the pinned schema to be used, got %+v", errs)
+	}
+	if errs := r.ValidateArgs("email.send", "urn:ojs:schema:email.send:v9", []byte(`[1]`)); errs != nil {
+		t.Errorf("expected an unregistered URI not to be checked, got %+v", errs)
+	}
+
+	if err := r.Register(ctx, &ArgsSchema{JobType: "x", Schema: json.RawMessage(`{"type": 1}`)}); err == nil {
+		t.Error("expected an invalid schema to be rejected")
+	}
+	if err := r.Register(ctx, &ArgsSchema{Schema: json.RawMessage(`{}`)}); err == nil {
+		t.Error("expected a schema without a job type to be rejected")
+	}
+}
+
+func TestRegistryPersists(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	r, err := NewRegistry(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Register(ctx, &ArgsSchema{JobType: "email.send", Schema: json.RawMessage(emailArgs), Source: "api"})
+	r.Register(ctx, &ArgsSchema{URI: "urn:ojs:schema:report:v1", JobType: "report", Schema: json.RawMessage(`{"type": "array"}`)})
+	if err := r.Delete(ctx, "urn:ojs:schema:report:v1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete(ctx, "urn:ojs:schema:report:v1"); err != ErrSchemaNotFound {
+		t.Errorf("expected ErrSchemaNotFound, got %v", err)
+	}
+
+	reloaded, err := NewRegistry(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas := reloaded.List("")
+	if len(schemas) != 1 || schemas[0].JobType != "email.send" || schemas[0].Source != "api" {
+		t.Fatalf("expected the email.send schema to be restored, got %+v", schemas)
+	}
+	if errs := reloaded.ValidateArgs("email.send", "", []byte(`["nope"]`)); len(errs) != 1 {
+		t.Errorf("expected the restored schema to be enforced, got %+v", errs)
+	}
+
+	store.Close()
+	if err := reloaded.Delete(ctx, schemas[0].URI); err == nil {
+		t.Fatal("expected delete to fail with the store closed")
+	}
+	if _, ok := reloaded.Get(schemas[0].URI); !ok {
+		t.Error("expected a schema that failed to delete to be kept")
+	}
+}
@@ -0,0 +1,177 @@
+// Package schema validates jobs against the OJS JSON Schemas bundled with
+// the UI, giving the server the same checks as the browser editor.
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Job is the name of the OJS job envelope schema.
+const Job = "job"
+
+// ErrUnknownSchema is returned when validating against a schema that is
+// not loaded.
+var ErrUnknownSchema = errors.New("unknown schema")
+
+var printer = message.NewPrinter(language.English)
+
+// Error is one schema violation. Path is a JSON Pointer to the offending
+// value, as reported by the UI's validator.
+type Error struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	Keyword string `json:"keyword"`
+}
+
+// Validator validates documents against a set of named schemas.
+type Validator struct {
+	schemas map[string]*jsonschema.Schema
+}
+
+// New compiles every *.schema.json file in fsys. A schema is named after
+// its file, so job.schema.json is "job". Schemas may $ref each other by
+// their $id.
+func New(fsys fs.FS) (*Validator, error) {
+	files, err := fs.Glob(fsys, "*.schema.json")
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no schemas found")
+	}
+
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	ids := make(map[string]string, len(files))
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", file, err)
+		}
+		id, _ := doc.(map[string]any)["$id"].(string)
+		if id == "" {
+			id = "file:///" + file
+		}
+		if err := c.AddResource(id, doc); err != nil {
+			return nil, fmt.Errorf("add %s: %w", file, err)
+		}
+		ids[strings.TrimSuffix(path.Base(file), ".schema.json")] = id
+	}
+
+	v := &Validator{schemas: make(map[string]*jsonschema.Schema, len(ids))}
+	for name, id := range ids {
+		sch, err := c.Compile(id)
+		if err != nil {
+			return nil, fmt.Errorf("compile %s: %w", name, err)
+		}
+		v.schemas[name] = sch
+	}
+	if _, ok := v.schemas[Job]; !ok {
+		return nil, fmt.Errorf("job schema not found")
+	}
+	return v, nil
+}
+
+// Names returns the names of the loaded schemas.
+func (v *Validator) Names() []string {
+	names := make([]string, 0, len(v.schemas))
+	for name := range v.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks a JSON document against the named schema and returns
+// its violations. It fails only if the schema is unknown or the document
+// is not JSON.
+func (v *Validator) Validate(name string, data []byte) ([]Error, error) {
+	sch, ok := v.schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownSchema, name)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return validate(sch, doc), nil
+}
+
+func validate(sch *jsonschema.Schema, doc any) []Error {
+	err := sch.Validate(doc)
+	if err == nil {
+		return nil
+	}
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []Error{{Path: "/", Message: err.Error(), Keyword: "schema"}}
+	}
+	var errs []Error
+	collect(ve, &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs
+}
+
+// collect appends the leaf violations of ve. A missing required property
+// is reported at the property's own path.
+func collect(ve *jsonschema.ValidationError, errs *[]Error) {
+	if len(ve.Causes) > 0 {
+		for _, c := range ve.Causes {
+			collect(c, errs)
+		}
+		return
+	}
+	keyword := ""
+	if kp := ve.ErrorKind.KeywordPath(); len(kp) > 0 {
+		keyword = kp[len(kp)-1]
+	}
+	if req, ok := ve.ErrorKind.(*kind.Required); ok {
+		for _, prop := range req.Missing {
+			*errs = append(*errs, Error{
+				Path:    pointer(append(append([]string(nil), ve.InstanceLocation...), prop)),
+				Message: "is required",
+				Keyword: keyword,
+			})
+		}
+		return
+	}
+	*errs = append(*errs, Error{
+		Path:    pointer(ve.InstanceLocation),
+		Message: ve.ErrorKind.LocalizedString(printer),
+		Keyword: keyword,
+	})
+}
+
+// pointer formats a location as a JSON Pointer, "/" for the root.
+func pointer(loc []string) string {
+	if len(loc) == 0 {
+		return "/"
+	}
+	var sb strings.Builder
+	for _, tok := range loc {
+		tok = strings.ReplaceAll(tok, "~", "~0")
+		tok = strings.ReplaceAll(tok, "/", "~1")
+		sb.WriteString("/" + tok)
+	}
+	return sb.String()
+}
+
+// ParseError reports a document that is not JSON the way the UI does.
+func ParseError(err error) Error {
+	return Error{Path: "/", Message: err.Error(), Keyword: "parse"}
+}
@@ -0,0 +1,169 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	spaembed "github.com/openjobspec/ojs-playground/server/internal/embed"
+)
+
+func newTestValidator(t *testing.T) *Validator {
+	t.Helper()
+	v, err := New(spaembed.Schemas())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func paths(errs []Error) map[string]bool {
+	m := make(map[string]bool, len(errs))
+	for _, e := range errs {
+		m[e.Path] = true
+	}
+	return m
+}
+
+func TestLoadsBundledSchemas(t *testing.T) {
+	v := newTestValidator(t)
+	want := []string{"error", "job", "retry-policy", "unique-policy"}
+	got := v.Names()
+	if len(got) != len(want) {
+		t.Fatalf("expected schemas %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected schemas %v, got %v", want, got)
+		}
+	}
+}
+
+func TestValidateJobEnvelope(t *testing.T) {
+	v := newTestValidator(t)
+
+	errs, err := v.Validate(Job, []byte(`{
+		"specversion": "1.0.0-rc.1",
+		"id": "019461a8-1a2b-7c3d-8e4f-5a6b7c8d9e0f",
+		"type": "email.send",
+		"queue": "default",
+		"args": ["user@example.com"],
+		"retry": {"max_attempts": 5, "initial_interval": "PT1S"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected a valid envelope, got %+v", errs)
+	}
+
+	errs, _ = v.Validate(Job, []byte(`{
+		"specversion": "0.9",
+		"type": "Email Send",
+		"queue": "default",
+		"args": [],
+		"retry": {"max_attempts": -1}
+	}`))
+	got := paths(errs)
+	for _, want := range []string{"/specversion", "/id", "/type", "/retry/max_attempts"} {
+		if !got[want] {
+			t.Errorf("expected an error at %s, got %+v", want, errs)
+		}
+	}
+
+	if _, err := v.Validate("nope", []byte(`{}`)); err == nil {
+		t.Error("expected an error for an unknown schema")
+	}
+}
+
+func TestValidateEnqueue(t *testing.T) {
+	v := newTestValidator(t)
+
+	errs, err := v.ValidateEnqueue([]byte(`{
+		"type": "email.send",
+		"args": ["user@example.com"],
+		"options": {"queue": "email", "retry": {"max_attempts": 3}, "timeout": {"execution": 30}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected a valid request, got %+v", errs)
+	}
+
+	errs, _ = v.ValidateEnqueue([]byte(`{
+		"args": {"to": "x"},
+		"options": {"queue": "Email", "retry": {"max_attempts": -1}, "expires_at": "tomorrow"}
+	}`))
+	got := paths(errs)
+	for _, want := range []string{"/type", "/args", "/options/queue", "/options/retry/max_attempts", "/options/expires_at"} {
+		if !got[want] {
+			t.Errorf("expected an error at %s, got %+v", want, errs)
+		}
+	}
+	for _, unwanted := range []string{"/id", "/specversion"} {
+		if got[unwanted] {
+			t.Errorf("expected no error for server-assigned %s, got %+v", unwanted, errs)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	v := newTestValidator(t)
+	reached := false
+	h := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		reached = false
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("POST", path, bytes.NewBufferString(body)))
+		return rr
+	}
+
+	rr := post("/ojs/v1/jobs", `{"type": "email.send", "args": [], "options": {"priority": "high"}}`)
+	if rr.Code != http.StatusUnprocessableEntity || reached {
+		t.Fatalf("expected 422 before the backend, got %d", rr.Code)
+	}
+	var resp struct {
+		Error struct {
+			Code    string `json:"code"`
+			Details []struct {
+				Path string `json:"path"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Error.Code != "validation_error" || len(resp.Error.Details) != 1 || resp.Error.Details[0].Path != "options.priority" {
+		t.Errorf("expected a validation_error for options.priority, got %s", rr.Body.String())
+	}
+
+	if rr := post("/ojs/v1/jobs", `{"type": "email.send", "args": []}`); rr.Code != http.StatusCreated || !reached {
+		t.Errorf("expected a valid job to reach the backend, got %d", rr.Code)
+	}
+	rr = post("/ojs/v1/jobs/batch", `{"jobs": [{"type": "a", "args": []}, {"type": "b", "args": {}}]}`)
+	if rr.Code != http.StatusUnprocessableEntity || reached {
+		t.Fatalf("expected 422 for a batch with an invalid job, got %d", rr.Code)
+	}
+	resp.Error.Details = nil
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Path != "jobs.1.args" {
+		t.Errorf("expected a validation_error for jobs.1.args, got %s", rr.Body.String())
+	}
+	if rr := post("/ojs/v1/jobs/batch", `{"jobs": [{"type": "a", "args": []}]}`); rr.Code != http.StatusCreated || !reached {
+		t.Errorf("expected a valid batch to reach the backend, got %d", rr.Code)
+	}
+	if rr := post("/api/jobs", `{"type": "email.send", "args": [], "options": {"priority": "high"}}`); rr.Code != http.StatusUnprocessableEntity || reached {
+		t.Errorf("expected 422 for an invalid playground job, got %d", rr.Code)
+	}
+	if post("/ojs/v1/jobs", `not json`); !reached {
+		t.Error("expected malformed JSON to be left to the backend")
+	}
+	if post("/ojs/v1/workers/fetch", `{"queues": 1}`); !reached {
+		t.Error("expected other endpoints to be passed through")
+	}
+}
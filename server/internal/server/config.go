@@ -4,10 +4,12 @@ package server
 type Config struct {
 	Port        int
 	Backends    []string
+	BackendURLs map[string]string // name -> OJS base URL
 	RedisURL    string
 	PostgresURL string
 	ScanPorts   string
 	NoScan      bool
+	NoSnapshot  bool
 	OpenBrowser bool
 	Verbose     bool
 	DataDir     string
@@ -22,6 +24,7 @@ func DefaultConfig() *Config {
 		PostgresURL: "postgres://localhost:5432/ojs?sslmode=disable",
 		ScanPorts:   "3000-9999",
 		NoScan:      false,
+		NoSnapshot:  false,
 		OpenBrowser: true,
 		Verbose:     false,
 		DataDir:     "",
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -9,9 +10,11 @@ import (
 	"github.com/openjobspec/ojs-playground/server/internal/api"
 	"github.com/openjobspec/ojs-playground/server/internal/backends"
 	"github.com/openjobspec/ojs-playground/server/internal/chaos"
+	"github.com/openjobspec/ojs-playground/server/internal/cron"
 	"github.com/openjobspec/ojs-playground/server/internal/discovery"
 	"github.com/openjobspec/ojs-playground/server/internal/history"
 	"github.com/openjobspec/ojs-playground/server/internal/proxy"
+	"github.com/openjobspec/ojs-playground/server/internal/schema"
 	"github.com/openjobspec/ojs-playground/server/internal/sse"
 
 	spaembed "github.com/openjobspec/ojs-playground/server/internal/embed"
@@ -26,10 +29,21 @@ type Deps struct {
 	MemoryBackend  *backends.MemoryBackend
 	ChaosConfig    *chaos.Config
 	WorkerRegistry *discovery.Registry
+	CronRegistry   *cron.Registry
+	SnapshotStore  *backends.SnapshotStore
+	Validator      *schema.Validator
+	SchemaRegistry *schema.Registry
 }
 
-// NewRouter creates and configures the HTTP router with all routes.
-func NewRouter(deps *Deps) http.Handler {
+// ojsRouter is implemented by in-process backends that serve the OJS HTTP
+// endpoints themselves.
+type ojsRouter interface {
+	Router() chi.Router
+}
+
+// NewRouter creates and configures the HTTP router with all routes. It
+// fails if the active backend's OJS endpoints cannot be served.
+func NewRouter(deps *Deps) (http.Handler, error) {
 	r := chi.NewRouter()
 
 	// Global middleware
@@ -46,31 +60,48 @@ func NewRouter(deps *Deps) http.Handler {
 		MemoryBackend:  deps.MemoryBackend,
 		ChaosConfig:    deps.ChaosConfig,
 		WorkerRegistry: deps.WorkerRegistry,
+		CronRegistry:   deps.CronRegistry,
+		SnapshotStore:  deps.SnapshotStore,
+		Validator:      deps.Validator,
+		SchemaRegistry: deps.SchemaRegistry,
 		Port:           deps.Config.Port,
 		BackendNames:   deps.Config.Backends,
 	}
 	api.RegisterRoutes(r, routeDeps)
 
-	// OJS protocol routes: mount memory backend directly or proxy to external backend
-	if deps.MemoryBackend != nil && deps.BackendManager.ActiveName() == "memory" {
-		// Mount in-memory backend as chi sub-router at /ojs/v1
-		r.Mount("/ojs/v1", deps.MemoryBackend.Router())
+	// OJS protocol routes: mount an in-process backend directly or proxy to
+	// an external one
+	active, err := deps.BackendManager.Active()
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := active.(ojsRouter); ok {
+		r.Route("/ojs/v1", func(r chi.Router) {
+			if deps.Validator != nil {
+				r.Use(deps.Validator.Middleware)
+			}
+			r.Mount("/", b.Router())
+		})
 	} else {
+		if active.URL() == "" {
+			return nil, fmt.Errorf("backend %q has no OJS endpoints to serve", active.Name())
+		}
 		// Proxy to external backend with chaos interceptor
-		active, err := deps.BackendManager.Active()
-		if err == nil && active.URL() != "" {
-			p, err := proxy.NewProxy(active.URL(), deps.Store, deps.Broadcaster, active.Name())
-			if err == nil {
-				r.Route("/ojs", func(r chi.Router) {
-					r.Use(proxy.ChaosInterceptor(deps.ChaosConfig))
-					r.Handle("/*", p)
-				})
-			}
+		p, err := proxy.NewProxy(active.URL(), deps.Store, deps.Broadcaster, active.Name())
+		if err != nil {
+			return nil, fmt.Errorf("proxy to backend %q: %w", active.Name(), err)
 		}
+		r.Route("/ojs", func(r chi.Router) {
+			r.Use(proxy.ChaosInterceptor(deps.ChaosConfig))
+			if deps.Validator != nil {
+				r.Use(deps.Validator.Middleware)
+			}
+			r.Handle("/*", p)
+		})
 	}
 
 	// SPA catch-all (must be last)
 	r.Handle("/*", spaembed.SPAHandler())
 
-	return r
+	return r, nil
 }
@@ -8,9 +8,18 @@ const (
 	EventJobCompleted       = "job:completed"
 	EventJobFailed          = "job:failed"
 	EventJobDead            = "job:dead"
+	EventJobRateLimited     = "job:rate_limited"
+	EventJobProgress        = "job:progress"
+	EventJobExpired         = "job:expired"
 	EventWorkerConnected    = "worker:connected"
 	EventWorkerDisconnected = "worker:disconnected"
 	EventChaosActivated     = "chaos:activated"
+	EventCronFired          = "cron:fired"
+	EventQueuePaused        = "queue:paused"
+	EventQueueResumed       = "queue:resumed"
+	EventWorkflowProgress   = "workflow:progress"
+	EventWorkflowCompleted  = "workflow:completed"
+	EventWorkflowFailed     = "workflow:failed"
 	EventKeepalive          = "keepalive"
 )
 